@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestListOperationCountryFilter(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"address\":{\"city\":\"Kyiv\",\"country\":\"UA\"}},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"address\":{\"city\":\"Kyiv\",\"country\":\"UA\"}}]"
+	args := Arguments{"operation": "list", "country": "ua", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestAddingOperationInvalidCountry(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"address\":{\"country\":\"XX\"}}", "fileName": fileName}
+
+	err := Perform(args, &buffer)
+
+	if code := ErrorCodeOf(err); code != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, code)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	findByAgeOp = "findByAge"
+	minAge      = "min"
+	maxAge      = "max"
+)
+
+func parseAgeBound(args Arguments, flagName string, defaultValue uint64) (uint64, error) {
+	value := args[flagName]
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	bound, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, invalidFlagError(flagName, value)
+	}
+	return bound, nil
+}
+
+func findUsersByAge(args Arguments, storage *fileStorage, writer io.Writer) error {
+	if len(args[minAge]) == 0 && len(args[maxAge]) == 0 {
+		return missingFlagError(minAge)
+	}
+	low, err := parseAgeBound(args, minAge, 0)
+	if err != nil {
+		return err
+	}
+	high, err := parseAgeBound(args, maxAge, math.MaxUint64)
+	if err != nil {
+		return err
+	}
+	if low > high {
+		return invalidFlagError(maxAge, args[maxAge])
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	found := []User{}
+	for _, user := range users {
+		if uint64(user.Age) >= low && uint64(user.Age) <= high {
+			found = append(found, user)
+		}
+	}
+	usersData, err := json.Marshal(found)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(usersData)
+	return nil
+}
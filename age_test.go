@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFindByAgeOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":18}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "findByAge", "min": "18", "max": "30", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":18}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestFindByAgeWrongRange(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "findByAge", "min": "30", "max": "18", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	birthdaysOp       = "birthdays"
+	withinDays        = "withinDays"
+	ageThreshold      = "ageThreshold"
+	dobLayout         = "2006-01-02"
+	defaultWithinDays = 30
+)
+
+type birthday struct {
+	Id     string `json:"id"`
+	Email  string `json:"email"`
+	Dob    string `json:"dob"`
+	Date   string `json:"date"`
+	Turns  int    `json:"turns"`
+	InDays int    `json:"inDays"`
+}
+
+func validateDob(dob string) error {
+	if len(dob) == 0 {
+		return nil
+	}
+	if _, err := time.Parse(dobLayout, dob); err != nil {
+		return newOperationError(CodeValidation, fmt.Errorf("Date of birth %s has to be in YYYY-MM-DD format", dob),
+			map[string]string{"dob": dob})
+	}
+	return nil
+}
+
+func upcomingBirthdays(withinDaysArg, ageThresholdArg string, storage *fileStorage, writer io.Writer) error {
+	days := defaultWithinDays
+	if len(withinDaysArg) > 0 {
+		var err error
+		days, err = strconv.Atoi(withinDaysArg)
+		if err != nil || days < 0 {
+			return invalidFlagError(withinDays, withinDaysArg)
+		}
+	}
+	threshold := 0
+	if len(ageThresholdArg) > 0 {
+		var err error
+		threshold, err = strconv.Atoi(ageThresholdArg)
+		if err != nil || threshold < 1 {
+			return invalidFlagError(ageThreshold, ageThresholdArg)
+		}
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	current := now()
+	today := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, time.UTC)
+	found := []birthday{}
+	for _, user := range users {
+		dob, err := time.Parse(dobLayout, user.Dob)
+		if err != nil {
+			continue
+		}
+		next := time.Date(today.Year(), dob.Month(), dob.Day(), 0, 0, 0, 0, time.UTC)
+		if next.Before(today) {
+			next = time.Date(today.Year()+1, dob.Month(), dob.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		inDays := int(next.Sub(today).Hours() / 24)
+		turns := next.Year() - dob.Year()
+		if inDays > days || (threshold > 0 && turns != threshold) {
+			continue
+		}
+		found = append(found, birthday{Id: user.Id, Email: user.Email, Dob: user.Dob,
+			Date: next.Format(dobLayout), Turns: turns, InDays: inDays})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].InDays < found[j].InDays
+	})
+	birthdaysData, err := json.Marshal(found)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(birthdaysData)
+	return nil
+}
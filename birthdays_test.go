@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBirthdaysOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	DefaultClock = FixedClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	defer func() { DefaultClock = ClockFunc(time.Now) }()
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"a@test.com\",\"age\":17,\"dob\":\"2006-05-20\"},{\"id\":\"2\",\"email\":\"b@test.com\",\"age\":31,\"dob\":\"1993-05-03\"},{\"id\":\"3\",\"email\":\"c@test.com\",\"age\":40,\"dob\":\"1984-09-01\"}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"2\",\"email\":\"b@test.com\",\"dob\":\"1993-05-03\",\"date\":\"2024-05-03\",\"turns\":31,\"inDays\":2},{\"id\":\"1\",\"email\":\"a@test.com\",\"dob\":\"2006-05-20\",\"date\":\"2024-05-20\",\"turns\":18,\"inDays\":19}]"
+	args := Arguments{"operation": "birthdays", "withinDays": "30", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	buffer.Reset()
+	args["ageThreshold"] = "18"
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput = "[{\"id\":\"1\",\"email\":\"a@test.com\",\"dob\":\"2006-05-20\",\"date\":\"2024-05-20\",\"turns\":18,\"inDays\":19}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// catalogTemplate returns the source of the template:<name> format, the named
+// template from the [templates] section of the config file.
+func catalogTemplate(args Arguments) (string, error) {
+	name := strings.TrimPrefix(args[format], catalogFormatPrefix)
+	settings, err := loadConfig(args[configFile])
+	if err != nil {
+		return "", err
+	}
+	source, ok := settings[templatesSection][name]
+	if !ok {
+		return "", newOperationError(CodeNotFound, fmt.Errorf("Template %s is not defined in config file %s", name, args[configFile]),
+			map[string]string{format: args[format], configFile: args[configFile]})
+	}
+	return source, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestExportCatalogTemplate(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("# report layouts\n[templates]\naudit = \"{{.Id}},{{.Email}}\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "export", "format": "template:audit", "config": configFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "1,test@test.com\n2,test2@test.com\n"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	buffer.Reset()
+	args[format] = "template:missing"
+	err = Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeNotFound {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeNotFound, ErrorCodeOf(err))
+	}
+}
+
+func TestListCatalogTemplate(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("[templates]\naudit = \"{{.Id}},{{.Email}}\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "list", "format": "template:audit", "config": configFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "1,test@test.com\n"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	err = Perform(Arguments{"operation": "head", "format": "template:audit", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+const (
+	changeIdOp = "changeId"
+	newId      = "newId"
+)
+
+// changeUserId re-keys a user in a single save, so the record can not be lost
+// half way as with remove followed by add.
+func changeUserId(oldId, newIdArg, idTypeArg string, pattern *regexp.Regexp, storage *fileStorage, writer io.Writer) error {
+	if len(newIdArg) == 0 {
+		return missingFlagError(newId)
+	}
+	if err := validateIdFormat(newIdArg, idTypeArg); err != nil {
+		return err
+	}
+	if err := validateIdPattern(newIdArg, pattern); err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	found := -1
+	for i, user := range users {
+		if user.Id == newIdArg && newIdArg != oldId {
+			return newOperationError(CodeConflict, fmt.Errorf("Item with id %s already exists", newIdArg),
+				map[string]string{id: newIdArg})
+		}
+		if user.Id == oldId {
+			found = i
+		}
+	}
+	if found < 0 {
+		storage.diagnostics.Write([]byte(fmt.Sprintf(userNotFoundMsg, oldId)))
+		return nil
+	}
+	users[found].Id = newIdArg
+	return storage.save(users)
+}
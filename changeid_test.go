@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestChangeIdOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "changeId", "id": "1", "newId": "7", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"7\",\"email\":\"test@test.com\",\"age\":23},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestChangeIdOperationExistingId(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "changeId", "id": "1", "newId": "2", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, ErrorCodeOf(err))
+	}
+}
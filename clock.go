@@ -0,0 +1,52 @@
+package main
+
+import (
+	"time"
+)
+
+const nowFlag = "now"
+
+// Clock is the time source used by operations that depend on the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator produces ids of the given -idType that do not collide with existing users.
+type IDGenerator interface {
+	NewID(idType string, existing []User) (string, error)
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock returns a Clock that always reports t.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// DefaultClock and DefaultIDGenerator are used by Perform. Tests swap them for
+// a fixed time and predictable ids.
+var (
+	DefaultClock       Clock       = ClockFunc(time.Now)
+	DefaultIDGenerator IDGenerator = randomIDGenerator{}
+)
+
+func now() time.Time {
+	return DefaultClock.Now()
+}
+
+func configureClock(nowArg string) error {
+	if len(nowArg) == 0 {
+		return nil
+	}
+	fixed, err := time.Parse(time.RFC3339, nowArg)
+	if err != nil {
+		return invalidFlagError(nowFlag, nowArg)
+	}
+	DefaultClock = FixedClock(fixed)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	strictTypes = "strictTypes"
+	ageField    = "age"
+)
+
+// coerceItemAges rewrites ages given as floats with no fraction or as numeric
+// strings, like "23.0" from spreadsheets, into integers. item may hold a
+// single user or an array of users.
+func coerceItemAges(item string) (string, error) {
+	trimmed := strings.TrimSpace(item)
+	if len(trimmed) == 0 {
+		return item, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var records []map[string]interface{}
+	isArray := strings.HasPrefix(trimmed, "[")
+	if isArray {
+		err := decoder.Decode(&records)
+		if err != nil {
+			return item, nil
+		}
+	} else {
+		var record map[string]interface{}
+		err := decoder.Decode(&record)
+		if err != nil {
+			return item, nil
+		}
+		records = []map[string]interface{}{record}
+	}
+	changed := false
+	for _, record := range records {
+		value, ok := record[ageField]
+		if number, isNumber := value.(json.Number); !ok || value == nil || (isNumber && isUintNumber(number)) {
+			continue
+		}
+		age, err := lenientAge(fmt.Sprint(value))
+		if err != nil {
+			return "", err
+		}
+		record[ageField] = age
+		changed = true
+	}
+	if !changed {
+		return item, nil
+	}
+	var itemData []byte
+	var err error
+	if isArray {
+		itemData, err = marshalWithoutEscaping(records)
+	} else {
+		itemData, err = marshalWithoutEscaping(records[0])
+	}
+	if err != nil {
+		return "", newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	return string(itemData), nil
+}
+
+// lenientAge parses an age written as an integer, a float with no fraction
+// or either of them quoted.
+func lenientAge(value string) (uint, error) {
+	value = strings.TrimSpace(value)
+	if age, err := strconv.ParseUint(value, 10, 0); err == nil {
+		return uint(age), nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || number != float64(uint64(number)) {
+		return 0, newOperationError(CodeValidation, fmt.Errorf("Age %s is not a whole non-negative number", value),
+			map[string]string{"field": ageField, "value": value})
+	}
+	return uint(number), nil
+}
+
+func isUintNumber(number json.Number) bool {
+	_, err := strconv.ParseUint(number.String(), 10, 0)
+	return err == nil
+}
+
+func marshalWithoutEscaping(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), err
+}
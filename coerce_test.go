@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAddingUserWithSpreadsheetAge(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":\"23.0\"}", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestAddingUserWithFractionalAge(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23.5}", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
+
+func TestStrictTypesRejectStringAge(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":\"23\"}", "strictTypes": "true", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
+
+func TestAddingUserWithSpreadsheetAgeSet(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "set": "id=1\nemail=test@test.com\nage=23.0", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const compactOp = "compact"
+
+// compactUsers rewrites the users file in a canonical, diff friendly form:
+// indented, sorted by id and without repeated records. Records sharing an id
+// but differing otherwise are left for dedupe to resolve.
+func compactUsers(storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	seen := map[string]string{}
+	unique := make([]User, 0, len(users))
+	for _, user := range users {
+		userData, err := json.Marshal(user)
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		previous, ok := seen[user.Id]
+		if ok && previous == string(userData) {
+			continue
+		}
+		if ok {
+			return newOperationError(CodeConflict,
+				fmt.Errorf("Records with id %s differ, run %s before %s", user.Id, dedupeOp, compactOp),
+				map[string]string{id: user.Id})
+		}
+		seen[user.Id] = string(userData)
+		unique = append(unique, user)
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return lessValue(unique[i].Id, unique[j].Id)
+	})
+	storage.layout.indented = true
+	err = storage.save(unique)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "%d users written, %d duplicates removed", len(unique), len(users)-len(unique))
+	return nil
+}
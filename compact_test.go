@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCompactOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"10\",\"email\":\"test10@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31,\"metadata\":{\"team\":\"a\",\"role\":\"dev\"}},{\"id\":\"10\",\"email\":\"test10@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "compact", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "2 users written, 1 duplicates removed"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := `[
+  {
+    "id": "2",
+    "email": "test2@test.com",
+    "age": 31,
+    "metadata": {
+      "role": "dev",
+      "team": "a"
+    }
+  },
+  {
+    "id": "10",
+    "email": "test10@test.com",
+    "age": 34
+  },
+  {
+    "id": "3",
+    "email": "test3@test.com",
+    "age": 22
+  }
+]
+`
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
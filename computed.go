@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const computedSection = "computed"
+
+// computedField is an output only field evaluated for every user at list and
+// export time. Fields are configured in the [computed] section of the config
+// file, for example:
+//
+//	domain = "emailDomain(email)"
+//	isAdult = "age >= 18"
+type computedField struct {
+	name string
+	eval func(user User) interface{}
+}
+
+var computedCall = regexp.MustCompile(`^(\w+)\(\s*([\w.]+)\s*\)$`)
+
+var computedFunctions = map[string]func(value string) interface{}{
+	"emailDomain": func(value string) interface{} { return emailDomain(value) },
+	"lower":       func(value string) interface{} { return strings.ToLower(value) },
+	"upper":       func(value string) interface{} { return strings.ToUpper(value) },
+	"length":      func(value string) interface{} { return len([]rune(value)) },
+}
+
+func loadComputedFields(args Arguments) ([]computedField, error) {
+	if len(args[configFile]) == 0 {
+		return nil, nil
+	}
+	settings, err := loadConfig(args[configFile])
+	if err != nil {
+		return nil, err
+	}
+	userFieldNames, err := userFields(User{Address: &Address{}, Invite: &Invite{}, Metadata: map[string]string{"": ""}})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(settings[computedSection]))
+	for name := range settings[computedSection] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]computedField, 0, len(names))
+	for _, name := range names {
+		if _, ok := userFieldNames[name]; ok {
+			return nil, computedFieldError(name, "shadows a user field")
+		}
+		eval, err := parseComputedExpression(settings[computedSection][name])
+		if err != nil {
+			return nil, computedFieldError(name, err.Error())
+		}
+		fields = append(fields, computedField{name: name, eval: eval})
+	}
+	return fields, nil
+}
+
+// parseComputedExpression accepts a function call on a user field, a bare
+// user field or a filter condition, which yields a boolean.
+func parseComputedExpression(expression string) (func(user User) interface{}, error) {
+	expression = strings.TrimSpace(expression)
+	if call := computedCall.FindStringSubmatch(expression); call != nil {
+		function, ok := computedFunctions[call[1]]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %s", call[1])
+		}
+		if _, ok := userFieldValue(User{}, call[2]); !ok {
+			return nil, fmt.Errorf("unknown field %s", call[2])
+		}
+		return func(user User) interface{} {
+			value, _ := userFieldValue(user, call[2])
+			return function(value)
+		}, nil
+	}
+	if _, ok := userFieldValue(User{}, expression); ok {
+		return func(user User) interface{} {
+			value, _ := userFieldValue(user, expression)
+			return value
+		}, nil
+	}
+	match, err := parseFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	return func(user User) interface{} { return match(user) }, nil
+}
+
+func computedFieldError(name, problem string) error {
+	return newOperationError(CodeValidation, fmt.Errorf("Computed field %s %s", name, problem),
+		map[string]string{"field": name})
+}
+
+// marshalUsers encodes users with the computed fields appended to every
+// object, after the stored fields.
+func marshalUsers(users []User, fields []computedField) ([]byte, error) {
+	if len(fields) == 0 {
+		usersData, err := json.Marshal(users)
+		if err != nil {
+			return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		return usersData, nil
+	}
+	var usersData bytes.Buffer
+	usersData.WriteString("[")
+	for i, user := range users {
+		if i > 0 {
+			usersData.WriteString(",")
+		}
+		userData, err := json.Marshal(user)
+		if err != nil {
+			return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		usersData.Write(userData[:len(userData)-1])
+		for _, field := range fields {
+			valueData, err := json.Marshal(field.eval(user))
+			if err != nil {
+				return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+			}
+			fmt.Fprintf(&usersData, ",%q:%s", field.name, valueData)
+		}
+		usersData.WriteString("}")
+	}
+	usersData.WriteString("]")
+	return usersData.Bytes(), nil
+}
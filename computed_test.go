@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestListWithComputedFields(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@Test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.org\",\"age\":17}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("[computed]\ndomain = \"emailDomain(email)\"\nisAdult = \"age >= 18\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "list", "config": configFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"test@Test.com\",\"age\":34,\"domain\":\"test.com\",\"isAdult\":true}," +
+		"{\"id\":\"2\",\"email\":\"test2@test.org\",\"age\":17,\"domain\":\"test.org\",\"isAdult\":false}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestComputedFieldShadowingUserField(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+	defer os.Remove(fileName)
+
+	err := ioutil.WriteFile(configFileName, []byte("[computed]\nage = \"length(email)\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "list", "config": configFileName, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	configFile          = "config"
+	templatesSection    = "templates"
+	catalogFormatPrefix = "template:"
+)
+
+// config holds the sections of the configuration file. The file uses a small
+// TOML subset: [section] headers, key = "value" pairs and # comments.
+type config map[string]map[string]string
+
+func loadConfig(configArg string) (config, error) {
+	if len(configArg) == 0 {
+		return nil, missingFlagError(configFile)
+	}
+	data, err := os.ReadFile(osPath(configArg))
+	if err != nil {
+		return nil, newOperationError(CodeStorageIO, fmt.Errorf("Error while reading config file: %w", err),
+			map[string]string{configFile: configArg})
+	}
+	return parseConfig(string(data), configArg)
+}
+
+func parseConfig(data, configArg string) (config, error) {
+	settings := config{}
+	section := ""
+	for number, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if ok && strings.HasPrefix(value, `"`) {
+			value, ok = unquoteConfigValue(value)
+		}
+		if !ok || len(key) == 0 {
+			return nil, newOperationError(CodeValidation, fmt.Errorf("Invalid line %d in config file %s", number+1, configArg),
+				map[string]string{configFile: configArg, "line": strconv.Itoa(number + 1)})
+		}
+		if settings[section] == nil {
+			settings[section] = map[string]string{}
+		}
+		settings[section][key] = value
+	}
+	return settings, nil
+}
+
+func unquoteConfigValue(value string) (string, bool) {
+	end := strings.LastIndex(value, `"`)
+	if end == 0 {
+		return "", false
+	}
+	if rest := strings.TrimSpace(value[end+1:]); len(rest) > 0 && !strings.HasPrefix(rest, "#") {
+		return "", false
+	}
+	unquoted, err := strconv.Unquote(value[:end+1])
+	return unquoted, err == nil
+}
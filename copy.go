@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	copyOp = "copy"
+	move   = "move"
+)
+
+// copyUsers adds the users with the given ids to the -to file, by the
+// -onConflict policy, and with -move removes them from -fileName. The source
+// is saved after the target, and the target is restored if that save fails,
+// so a moved user can not be lost from both files. A user the target skipped
+// stays in the source.
+func copyUsers(ids []string, onConflictArg string, moveArg bool, storage, target *fileStorage, writer io.Writer) error {
+	if samePath(storage.fileName, target.fileName) {
+		return invalidFlagError(to, target.fileName)
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	targetUsers, err := target.load()
+	if err != nil {
+		return err
+	}
+	originalTarget := append([]User{}, targetUsers...)
+	positions := make(map[string]int, len(users))
+	for i, user := range users {
+		positions[user.Id] = i
+	}
+	moved := map[string]bool{}
+	changed := false
+	results := make([]recordResult, 0, len(ids))
+	for _, userId := range ids {
+		i, ok := positions[userId]
+		if !ok {
+			results = append(results, recordResult{Id: userId, Result: notFoundResult})
+			continue
+		}
+		var result string
+		targetUsers, result, err = applyConflictPolicy(targetUsers, users[i], onConflictArg)
+		if err != nil {
+			return withDetail(err, to, target.fileName)
+		}
+		if result != skippedResult {
+			changed = true
+			moved[userId] = true
+		}
+		results = append(results, recordResult{Id: userId, Result: result})
+	}
+	if changed {
+		err = target.save(targetUsers)
+		if err != nil {
+			return err
+		}
+	}
+	if moveArg && len(moved) > 0 {
+		remaining := make([]User, 0, len(users))
+		for _, user := range users {
+			if !moved[user.Id] {
+				remaining = append(remaining, user)
+			}
+		}
+		err = storage.save(remaining)
+		if err != nil {
+			if restoreErr := target.save(originalTarget); restoreErr != nil {
+				return withDetail(err, "restore", restoreErr.Error())
+			}
+			return err
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
+}
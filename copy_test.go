@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCopyOperationMove(t *testing.T) {
+	var buffer bytes.Buffer
+	toFileName := "other.json"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23},{\"id\":\"7\",\"email\":\"test7@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(toFileName, []byte("[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":40}]"), filePermission)
+	defer os.Remove(toFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "copy", "id": "7,9", "to": toFileName, "move": "true", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[{\"id\":\"7\",\"result\":\"added\"},{\"id\":\"9\",\"result\":\"not found\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+	bytes, err = ioutil.ReadFile(toFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent = "[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":40},{\"id\":\"7\",\"email\":\"test7@test.com\",\"age\":31}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestCopyOperationConflict(t *testing.T) {
+	var buffer bytes.Buffer
+	toFileName := "other.json"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(toFileName, []byte("[{\"id\":\"1\",\"email\":\"other@test.com\",\"age\":40}]"), filePermission)
+	defer os.Remove(toFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "copy", "id": "1", "to": toFileName, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	emailKey             = "emailKey"
+	emailKeyEnv          = "USERS_EMAIL_KEY"
+	encryptedEmailPrefix = "enc:v1:"
+)
+
+type emailCipher struct {
+	aead cipher.AEAD
+}
+
+func newEmailCipher(key string) (*emailCipher, error) {
+	rawKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(rawKey) != 32 {
+		return nil, newOperationError(CodeValidation, errors.New("-emailKey has to be a base64 encoded 32 byte key"),
+			map[string]string{"flag": emailKey})
+	}
+	block, err := aes.NewCipher(rawKey)
+	if err != nil {
+		return nil, newOperationError(CodeInternal, err, nil)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, newOperationError(CodeInternal, err, nil)
+	}
+	return &emailCipher{aead: aead}, nil
+}
+
+func (c *emailCipher) encrypt(email string) (string, error) {
+	if len(email) == 0 || strings.HasPrefix(email, encryptedEmailPrefix) {
+		return email, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", newOperationError(CodeInternal, fmt.Errorf("Error while encrypting email: %w", err), nil)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(email), nil)
+	return encryptedEmailPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *emailCipher) decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedEmailPrefix) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedEmailPrefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", newOperationError(CodeInvalidData, errors.New("Encrypted email is malformed"), nil)
+	}
+	nonceSize := c.aead.NonceSize()
+	email, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", newOperationError(CodeValidation, errors.New("Error while decrypting email, check -emailKey"), nil)
+	}
+	return string(email), nil
+}
+
+func hasEncryptedEmails(users []User) bool {
+	for _, user := range users {
+		if strings.HasPrefix(user.Email, encryptedEmailPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *emailCipher) decryptUsers(users []User) error {
+	for i := range users {
+		email, err := c.decrypt(users[i].Email)
+		if err != nil {
+			return err
+		}
+		users[i].Email = email
+		users[i].EmailAscii, err = c.decrypt(users[i].EmailAscii)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *emailCipher) encryptUsers(users []User) ([]User, error) {
+	encrypted := make([]User, len(users))
+	for i, user := range users {
+		email, err := c.encrypt(user.Email)
+		if err != nil {
+			return nil, err
+		}
+		user.Email = email
+		user.EmailAscii, err = c.encrypt(user.EmailAscii)
+		if err != nil {
+			return nil, err
+		}
+		encrypted[i] = user
+	}
+	return encrypted, nil
+}
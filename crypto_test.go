@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testEmailKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestEmailEncryption(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	item := "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}"
+	err := Perform(Arguments{"operation": "add", "item": item, "fileName": fileName, "emailKey": testEmailKey}, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if strings.Contains(string(bytes), "test@test.com") || !strings.Contains(string(bytes), "\"id\":\"1\"") {
+		t.Errorf("Expect only the email to be encrypted, but got %s", bytes)
+	}
+
+	err = Perform(Arguments{"operation": "findById", "id": "1", "fileName": fileName, "emailKey": testEmailKey}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	if buffer.String() != item {
+		t.Errorf("Expect output to be '%s', but got '%s'", item, buffer.String())
+	}
+}
+
+func TestEmailEncryptionCoversAsciiEmail(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	item := "{\"id\":\"1\",\"email\":\"jose@bücher.de\",\"age\":34}"
+	err := Perform(Arguments{"operation": "add", "item": item, "fileName": fileName, "emailKey": testEmailKey}, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if strings.Contains(string(bytes), "jose@") {
+		t.Errorf("Expect the ascii email to be encrypted too, but got %s", bytes)
+	}
+
+	err = Perform(Arguments{"operation": "findById", "id": "1", "fileName": fileName, "emailKey": testEmailKey}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "{\"id\":\"1\",\"email\":\"jose@bücher.de\",\"emailAscii\":\"jose@xn--bcher-kva.de\",\"age\":34}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestChangingEncryptedUsersWithoutKey(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	item := "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}"
+	err := Perform(Arguments{"operation": "add", "item": item, "fileName": fileName, "emailKey": testEmailKey}, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item = "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}"
+	err = Perform(Arguments{"operation": "add", "item": item, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if strings.Contains(string(bytes), "test2@test.com") {
+		t.Errorf("Expect no plaintext email to be saved, but got %s", bytes)
+	}
+}
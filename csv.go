@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const csvFormat = "csv"
+
+// writeUsersCSV writes users with a header row, using the column names of the
+// sql format followed by the computed fields.
+func writeUsersCSV(users []User, fields []computedField, writer io.Writer) error {
+	records := csv.NewWriter(writer)
+	header := append([]string{}, sqlColumns...)
+	for _, field := range fields {
+		header = append(header, field.name)
+	}
+	err := records.Write(header)
+	for _, user := range users {
+		if err != nil {
+			break
+		}
+		var row []string
+		row, err = csvRow(user, fields)
+		if err == nil {
+			err = records.Write(row)
+		}
+	}
+	if err == nil {
+		records.Flush()
+		err = records.Error()
+	}
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing CSV: %w", err), nil)
+	}
+	return nil
+}
+
+func csvRow(user User, fields []computedField) ([]string, error) {
+	address := Address{}
+	if user.Address != nil {
+		address = *user.Address
+	}
+	metadata := ""
+	if len(user.Metadata) > 0 {
+		metadataData, err := json.Marshal(user.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		metadata = string(metadataData)
+	}
+	row := []string{user.Id, user.Email, user.EmailAscii, strconv.FormatUint(uint64(user.Age), 10), user.Phone,
+		address.Street, address.City, address.Country, metadata, user.Dob, user.Status}
+	for _, field := range fields {
+		row = append(row, fmt.Sprint(field.eval(user)))
+	}
+	return row, nil
+}
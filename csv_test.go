@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestListAsCSV(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"address\":{\"city\":\"Kyiv, Podil\",\"country\":\"UA\"}},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31,\"metadata\":{\"team\":\"a\"}}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "list", "format": "csv", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "id,email,email_ascii,age,phone,street,city,country,metadata,dob,status\n" +
+		"1,test@test.com,,34,,,\"Kyiv, Podil\",UA,,,\n" +
+		"2,test2@test.com,,31,,,,,\"{\"\"team\"\":\"\"a\"\"}\",,\n"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestExportCSVToFile(t *testing.T) {
+	var buffer bytes.Buffer
+	outputFileName := "users.csv"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "export", "format": "csv", "output": outputFileName, "fileName": fileName}, &buffer)
+	defer os.Remove(outputFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(outputFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "id,email,email_ascii,age,phone,street,city,country,metadata,dob,status\n1,test@test.com,,34,,,,,,,\n"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	dedupeOp     = "dedupe"
+	keep         = "keep"
+	keepFirst    = "first"
+	keepLast     = "last"
+	byField      = "by"
+	dedupeById   = "id"
+	dedupeByMail = "email"
+	fuzzy        = "fuzzy"
+)
+
+// dedupeUsers removes users sharing an id, and with -by email also users
+// sharing an email, keeping the first or the last occurrence. With -fuzzy
+// emails within that edit distance of a kept one count as the same.
+func dedupeUsers(keepArg, byArg, fuzzyArg string, storage *fileStorage, writer io.Writer) error {
+	switch keepArg {
+	case "":
+		keepArg = keepFirst
+	case keepFirst, keepLast:
+	default:
+		return invalidFlagError(keep, keepArg)
+	}
+	byEmail := false
+	for _, key := range strings.Split(byArg, ",") {
+		switch strings.TrimSpace(key) {
+		case "", dedupeById:
+		case dedupeByMail:
+			byEmail = true
+		default:
+			return invalidFlagError(byField, byArg)
+		}
+	}
+	maxDistance := -1
+	if len(fuzzyArg) > 0 {
+		var err error
+		maxDistance, err = strconv.Atoi(fuzzyArg)
+		if err != nil || maxDistance < 0 {
+			return invalidFlagError(fuzzy, fuzzyArg)
+		}
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	kept := make([]bool, len(users))
+	seenIds := map[string]bool{}
+	seenEmails := map[string]bool{}
+	keptEmails := []string{}
+	for n := range users {
+		i := n
+		if keepArg == keepLast {
+			i = len(users) - 1 - n
+		}
+		emailKey := foldText(asciiEmail(users[i].Email))
+		if seenIds[users[i].Id] || (byEmail && len(emailKey) > 0 && seenEmails[emailKey]) ||
+			(len(emailKey) > 0 && nearEmail(emailKey, keptEmails, maxDistance)) {
+			continue
+		}
+		kept[i] = true
+		seenIds[users[i].Id] = true
+		seenEmails[emailKey] = true
+		if len(emailKey) > 0 {
+			keptEmails = append(keptEmails, emailKey)
+		}
+	}
+	results := []recordResult{}
+	remaining := make([]User, 0, len(users))
+	for i, user := range users {
+		if kept[i] {
+			remaining = append(remaining, user)
+			continue
+		}
+		results = append(results, recordResult{Id: user.Id, Result: removedResult})
+	}
+	if len(results) > 0 {
+		err = storage.save(remaining)
+		if err != nil {
+			return err
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
+}
+
+func nearEmail(emailKey string, keptEmails []string, maxDistance int) bool {
+	if maxDistance < 0 {
+		return false
+	}
+	for _, keptEmail := range keptEmails {
+		if levenshtein(emailKey, keptEmail) <= maxDistance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDedupeKeepsLastOccurrence(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":35}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "dedupe", "keep": "last", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"removed\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":35}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestDedupeByEmail(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"Test@Test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "dedupe", "by": "id,email", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"2\",\"result\":\"removed\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestDedupeFuzzyEmails(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"john@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"jonh@test.com\",\"age\":34},{\"id\":\"3\",\"email\":\"jane@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "dedupe", "fuzzy": "2", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"2\",\"result\":\"removed\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestDedupeWrongFuzzyDistance(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	err := Perform(Arguments{"operation": "dedupe", "fuzzy": "-1", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	diffOp = "diff"
+	from   = "from"
+)
+
+type usersDiff struct {
+	Added   []User       `json:"added"`
+	Removed []User       `json:"removed"`
+	Changed []userChange `json:"changed"`
+}
+
+type userChange struct {
+	Id     string   `json:"id"`
+	Fields []string `json:"fields"`
+	From   User     `json:"from"`
+	To     User     `json:"to"`
+}
+
+// diffUsers compares the -from users file with -fileName by id, so the
+// order of the records does not matter.
+func diffUsers(fromArg string, storage *fileStorage, writer io.Writer) error {
+	if len(fromArg) == 0 {
+		return missingFlagError(from)
+	}
+	fromData, err := os.ReadFile(osPath(fromArg))
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf(openFileErrorMsg, err),
+			map[string]string{from: fromArg})
+	}
+	fromUsers, _, err := decodeUsers(fromData)
+	if err != nil {
+		return withDetail(err, from, fromArg)
+	}
+	if storage.emails != nil {
+		err = storage.emails.decryptUsers(fromUsers)
+		if err != nil {
+			return err
+		}
+	}
+	if storage.masked != nil {
+		storage.masked.apply(fromUsers)
+	}
+	if storage.hideNotes {
+		stripNotes(fromUsers)
+	}
+	toUsers, err := storage.load()
+	if err != nil {
+		return err
+	}
+	diff, err := compareUsers(fromUsers, toUsers)
+	if err != nil {
+		return err
+	}
+	diffData, err := json.Marshal(diff)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(diffData)
+	return nil
+}
+
+func compareUsers(fromUsers, toUsers []User) (usersDiff, error) {
+	diff := usersDiff{Added: []User{}, Removed: []User{}, Changed: []userChange{}}
+	previous := make(map[string]User, len(fromUsers))
+	for _, user := range fromUsers {
+		previous[user.Id] = user
+	}
+	current := make(map[string]bool, len(toUsers))
+	for _, user := range toUsers {
+		current[user.Id] = true
+		fromUser, ok := previous[user.Id]
+		if !ok {
+			diff.Added = append(diff.Added, user)
+			continue
+		}
+		fields, err := changedFields(fromUser, user)
+		if err != nil {
+			return diff, err
+		}
+		if len(fields) > 0 {
+			diff.Changed = append(diff.Changed, userChange{Id: user.Id, Fields: fields, From: fromUser, To: user})
+		}
+	}
+	for _, user := range fromUsers {
+		if !current[user.Id] {
+			diff.Removed = append(diff.Removed, user)
+		}
+	}
+	sort.SliceStable(diff.Added, func(i, j int) bool { return lessValue(diff.Added[i].Id, diff.Added[j].Id) })
+	sort.SliceStable(diff.Removed, func(i, j int) bool { return lessValue(diff.Removed[i].Id, diff.Removed[j].Id) })
+	sort.SliceStable(diff.Changed, func(i, j int) bool { return lessValue(diff.Changed[i].Id, diff.Changed[j].Id) })
+	return diff, nil
+}
+
+func changedFields(a, b User) ([]string, error) {
+	aFields, err := userFields(a)
+	if err != nil {
+		return nil, err
+	}
+	bFields, err := userFields(b)
+	if err != nil {
+		return nil, err
+	}
+	fields := []string{}
+	for name, value := range aFields {
+		if string(bFields[name]) != string(value) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range bFields {
+		if _, ok := aFields[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func userFields(user User) (map[string]json.RawMessage, error) {
+	userData, err := json.Marshal(user)
+	if err != nil {
+		return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(userData, &fields)
+	if err != nil {
+		return nil, newOperationError(CodeInternal, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+	}
+	return fields, nil
+}
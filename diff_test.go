@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDiffOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	fromFileName := "from.json"
+
+	err := ioutil.WriteFile(fromFileName, []byte("[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fromFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":35},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "diff", "from": fromFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "{\"added\":[{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]," +
+		"\"removed\":[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]," +
+		"\"changed\":[{\"id\":\"1\",\"fields\":[\"age\"],\"from\":{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},\"to\":{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":35}}]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestDiffOperationHidesNotes(t *testing.T) {
+	var buffer bytes.Buffer
+	fromFileName := "from.json"
+	notes := "\"notes\":[{\"at\":\"2024-05-01T12:00:00Z\",\"text\":\"VIP\"}]"
+
+	err := ioutil.WriteFile(fromFileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,"+notes+"}]"), filePermission)
+	defer os.Remove(fromFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,"+notes+"}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "diff", "from": fromFileName, "hideNotes": "true", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "{\"added\":[],\"removed\":[],\"changed\":[]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	distinctOp = "distinct"
+	field      = "field"
+)
+
+type distinctValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+func distinctValues(fieldArg string, storage *fileStorage, writer io.Writer) error {
+	if _, ok := userFieldValue(User{}, fieldArg); !ok {
+		return invalidFlagError(field, fieldArg)
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, user := range users {
+		fieldValue, _ := userFieldValue(user, fieldArg)
+		counts[fieldValue]++
+	}
+	values := make([]distinctValue, 0, len(counts))
+	for fieldValue, count := range counts {
+		values = append(values, distinctValue{Value: fieldValue, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	valuesData, err := json.Marshal(values)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(valuesData)
+	return nil
+}
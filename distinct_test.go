@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDistinctOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"a@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"b@Test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"c@other.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"value\":\"test.com\",\"count\":2},{\"value\":\"other.com\",\"count\":1}]"
+	args := Arguments{"operation": "distinct", "field": "domain", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
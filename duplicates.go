@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const keepMerged = "merge"
+
+// duplicateOps lists the operations that read duplicate ids as stored, because
+// reporting or removing them is what they are for.
+var duplicateOps = map[string]bool{dedupeOp: true, compactOp: true, validateOp: true}
+
+func validateKeepArg(args Arguments) error {
+	if duplicateOps[args[operation]] {
+		return nil
+	}
+	switch args[keep] {
+	case "", keepFirst, keepLast, keepMerged:
+		return nil
+	}
+	return invalidFlagError(keep, args[keep])
+}
+
+// resolveDuplicateIds fails on users sharing an id unless -keep says which
+// record stays: the first, the last, or the first with the later ones merged
+// into it. The record that stays keeps the position of the first one, and the
+// resolved list is what the next save writes back.
+func resolveDuplicateIds(users []User, keepArg, fileName string) ([]User, error) {
+	positions := map[string]int{}
+	resolved := make([]User, 0, len(users))
+	for n, user := range users {
+		i, seen := positions[user.Id]
+		if !seen {
+			positions[user.Id] = len(resolved)
+			resolved = append(resolved, user)
+			continue
+		}
+		switch keepArg {
+		case keepFirst:
+		case keepLast:
+			resolved[i] = user
+		case keepMerged:
+			resolved[i] = mergeUsers(resolved[i], user)
+		default:
+			return nil, newOperationError(CodeDuplicateId,
+				fmt.Errorf("Users file has more than one user with id %s, pass -%s first|last|merge to resolve", user.Id, keep),
+				map[string]string{id: user.Id, userFileName: fileName, "index": strconv.Itoa(n)})
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDuplicateIdOnLoad(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23},{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "findById", "id": "1", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeDuplicateId {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeDuplicateId, ErrorCodeOf(err))
+	}
+}
+
+func TestDuplicateIdOnLoadKeepLast(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22},{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "findById", "id": "1", "keep": "last", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":31}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestDuplicateIdOnLoadMerge(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23,\"metadata\":{\"team\":\"a\"}},{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":31,\"metadata\":{\"role\":\"dev\"}}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "patch", "id": "1", "item": "{\"age\":32}", "keep": "merge", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":32,\"metadata\":{\"role\":\"dev\",\"team\":\"a\"}}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
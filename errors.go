@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrorCode classifies a failure returned from Perform independently of its
+// message. main turns it into the exit status and -errorFormat json output,
+// and tests check it instead of matching messages.
+type ErrorCode string
+
+const (
+	CodeValidation       ErrorCode = "VALIDATION"
+	CodeNotFound         ErrorCode = "NOT_FOUND"
+	CodeConflict         ErrorCode = "CONFLICT"
+	CodeStorageIO        ErrorCode = "STORAGE_IO"
+	CodeInvalidData      ErrorCode = "INVALID_DATA"
+	CodeTimeout          ErrorCode = "TIMEOUT"
+	CodeInternal         ErrorCode = "INTERNAL"
+	CodePermissionDenied ErrorCode = "PERMISSION_DENIED"
+	CodeDuplicateId      ErrorCode = "DUPLICATE_ID"
+)
+
+var exitCodes = map[ErrorCode]int{
+	CodeInternal:         1,
+	CodeValidation:       2,
+	CodeNotFound:         3,
+	CodeConflict:         4,
+	CodeStorageIO:        5,
+	CodeInvalidData:      6,
+	CodeTimeout:          7,
+	CodePermissionDenied: 8,
+	CodeDuplicateId:      9,
+}
+
+// ExitCode returns the process exit status used by the CLI for the code.
+func (c ErrorCode) ExitCode() int {
+	exitCode, ok := exitCodes[c]
+	if !ok {
+		return exitCodes[CodeInternal]
+	}
+	return exitCode
+}
+
+type operationError struct {
+	code    ErrorCode
+	details map[string]string
+	err     error
+}
+
+func newOperationError(code ErrorCode, err error, details map[string]string) error {
+	return &operationError{code: code, details: details, err: err}
+}
+
+// withDetail adds a detail to the error returned by a helper that does not
+// know the context it was called in, such as the file being decoded.
+func withDetail(err error, key, value string) error {
+	var opErr *operationError
+	if err == nil || !errors.As(err, &opErr) {
+		return err
+	}
+	details := map[string]string{key: value}
+	for detailKey, detailValue := range opErr.details {
+		details[detailKey] = detailValue
+	}
+	return &operationError{code: opErr.code, details: details, err: opErr.err}
+}
+
+func (e *operationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *operationError) Unwrap() error {
+	return e.err
+}
+
+// ErrorCodeOf returns the code carried by err, or CodeInternal if it has none.
+func ErrorCodeOf(err error) ErrorCode {
+	var opErr *operationError
+	if errors.As(err, &opErr) {
+		return opErr.code
+	}
+	return CodeInternal
+}
+
+func writeJSONError(err error, writer io.Writer) error {
+	report := struct {
+		Code    ErrorCode         `json:"code"`
+		Message string            `json:"message"`
+		Details map[string]string `json:"details,omitempty"`
+	}{Code: ErrorCodeOf(err), Message: err.Error()}
+	var opErr *operationError
+	if errors.As(err, &opErr) {
+		report.Details = opErr.details
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
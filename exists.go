@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+const existsOp = "exists"
+
+// userExists writes true or false, and for a missing user also returns a
+// NOT_FOUND error so that scripts can branch on the exit code alone.
+func userExists(idArg string, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.Id == idArg {
+			writer.Write([]byte("true"))
+			return nil
+		}
+	}
+	writer.Write([]byte("false"))
+	return newOperationError(CodeNotFound, fmt.Errorf(userNotFoundMsg, idArg), map[string]string{id: idArg})
+}
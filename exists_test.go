@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestExistsOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"5\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "exists", "id": "5", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	if buffer.String() != "true" {
+		t.Errorf("Expect output to be '%s', but got '%s'", "true", buffer.String())
+	}
+
+	buffer.Reset()
+	err = Perform(Arguments{"operation": "exists", "id": "6", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeNotFound {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeNotFound, ErrorCodeOf(err))
+	}
+	if buffer.String() != "false" {
+		t.Errorf("Expect output to be '%s', but got '%s'", "false", buffer.String())
+	}
+}
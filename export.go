@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	exportOp   = "export"
+	output     = "output"
+	chunkSize  = "chunkSize"
+	format     = "format"
+	jsonFormat = "json"
+)
+
+type exportManifest struct {
+	Total     int           `json:"total"`
+	ChunkSize int           `json:"chunkSize"`
+	Files     []exportChunk `json:"files"`
+}
+
+type exportChunk struct {
+	File  string `json:"file"`
+	Count int    `json:"count"`
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]*)?(.?)`)
+
+// validChunkPattern reports whether the -output of a chunked export has
+// exactly one %d verb, with optional flags and width such as %04d, and no
+// other verbs, so that every chunk gets its own file name.
+func validChunkPattern(pattern string) bool {
+	numbers := 0
+	for _, match := range formatVerb.FindAllStringSubmatch(pattern, -1) {
+		switch match[1] {
+		case "%":
+		case "d":
+			numbers++
+		default:
+			return false
+		}
+	}
+	return numbers == 1
+}
+
+func exportUsers(args Arguments, storage *fileStorage, writer io.Writer) error {
+	switch {
+	case args[format] == mailmergeFormat:
+		return exportMailMerge(args, storage, writer)
+	case args[format] == sqlFormat:
+		return exportSQL(args, storage, writer)
+	}
+	if err := validateUsersFormat(args[format]); err != nil {
+		return err
+	}
+	outputArg := args[output]
+	size := 0
+	if value := args[chunkSize]; len(value) > 0 {
+		var err error
+		size, err = strconv.Atoi(value)
+		if err != nil || size < 1 {
+			return invalidFlagError(chunkSize, value)
+		}
+		if len(outputArg) == 0 {
+			return missingFlagError(output)
+		}
+		if !validChunkPattern(outputArg) {
+			return invalidFlagError(output, outputArg)
+		}
+	}
+	fields, err := loadComputedFields(args)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		if len(outputArg) == 0 {
+			return writeUsersAs(args[format], args[templateSource], users, fields, writer)
+		}
+		return writeUsersFile(users, fields, args[format], args[templateSource], outputArg)
+	}
+
+	manifest := exportManifest{Total: len(users), ChunkSize: size, Files: []exportChunk{}}
+	for start, number := 0, 1; start < len(users); start, number = start+size, number+1 {
+		end := start + size
+		if end > len(users) {
+			end = len(users)
+		}
+		chunkFileName := fmt.Sprintf(outputArg, number)
+		err = writeUsersFile(users[start:end], fields, args[format], args[templateSource], chunkFileName)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, exportChunk{File: chunkFileName, Count: end - start})
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(manifestData)
+	return nil
+}
+
+// usersEncoder writes a list of users in an output format. The option is the
+// text after a colon in the format, as in xml:<root>, only accepted by the
+// formats of usersFormatOptions, or the template source of the template
+// format.
+type usersEncoder func(users []User, fields []computedField, option string, writer io.Writer) error
+
+// usersEncoders are the output formats of list, head, tail and export.
+var usersEncoders = map[string]usersEncoder{
+	"":             withoutOption(writeUsers),
+	jsonFormat:     withoutOption(writeUsers),
+	csvFormat:      withoutOption(writeUsersCSV),
+	tableFormat:    withoutOption(writeUsersTable),
+	yamlFormat:     withoutOption(writeUsersYAML),
+	ndjsonFormat:   withoutOption(writeUsersNDJSON),
+	xmlFormat:      writeUsersXML,
+	templateFormat: writeUsersTemplate,
+}
+
+var usersFormatOptions = map[string]bool{xmlFormat: true}
+
+func withoutOption(encode func(users []User, fields []computedField, writer io.Writer) error) usersEncoder {
+	return func(users []User, fields []computedField, option string, writer io.Writer) error {
+		return encode(users, fields, writer)
+	}
+}
+
+func usersEncoderOf(formatArg string) (usersEncoder, string, error) {
+	name, option, hasOption := strings.Cut(formatArg, ":")
+	encode, ok := usersEncoders[name]
+	if !ok || (hasOption && !usersFormatOptions[name]) {
+		return nil, "", invalidFlagError(format, formatArg)
+	}
+	return encode, option, nil
+}
+
+func validateUsersFormat(formatArg string) error {
+	_, _, err := usersEncoderOf(formatArg)
+	return err
+}
+
+func writeUsersAs(formatArg, sourceArg string, users []User, fields []computedField, writer io.Writer) error {
+	encode, option, err := usersEncoderOf(formatArg)
+	if err != nil {
+		return err
+	}
+	if formatArg == templateFormat {
+		if len(sourceArg) == 0 {
+			return missingFlagError(templateFile)
+		}
+		option = sourceArg
+	}
+	return encode(users, fields, option, writer)
+}
+
+func writeUsers(users []User, fields []computedField, writer io.Writer) error {
+	usersData, err := marshalUsers(users, fields)
+	if err != nil {
+		return err
+	}
+	writer.Write(usersData)
+	return nil
+}
+
+func writeUsersFile(users []User, fields []computedField, formatArg, sourceArg, outputFileName string) error {
+	file, err := os.OpenFile(osPath(outputFileName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while opening export file: %w", err),
+			map[string]string{output: outputFileName})
+	}
+	defer file.Close()
+	return writeUsersAs(formatArg, sourceArg, users, fields, file)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportOperationChunks(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove("chunk-0001.json")
+	defer os.Remove("chunk-0002.json")
+
+	expectedOutput := "{\"total\":3,\"chunkSize\":2,\"files\":[{\"file\":\"chunk-0001.json\",\"count\":2},{\"file\":\"chunk-0002.json\",\"count\":1}]}"
+	expectedLastChunk := "[{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"
+	args := Arguments{
+		"operation": "export",
+		"chunkSize": "2",
+		"output":    "chunk-%04d.json",
+		"fileName":  fileName,
+	}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile("chunk-0002.json")
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedLastChunk {
+		t.Errorf("Expect last chunk to be '%s', but got '%s'", expectedLastChunk, bytes)
+	}
+}
+
+func TestExportChunksWrongOutputPattern(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, pattern := range []string{"out%%.json", "out%s.json", "out%d-%d.json"} {
+		err = Perform(Arguments{"operation": "export", "chunkSize": "1", "output": pattern, "fileName": fileName}, &buffer)
+		if ErrorCodeOf(err) != CodeValidation {
+			t.Errorf("Expect error code of %s to be '%s', but got '%s'", pattern, CodeValidation, ErrorCodeOf(err))
+		}
+	}
+	matches, _ := filepath.Glob("out*.json")
+	if len(matches) > 0 {
+		t.Errorf("Expect no chunks to be written, but got %v", matches)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const fallback = "fallback"
+
+func validateFallbackArgs(args Arguments) error {
+	if len(args[fallback]) > 0 && samePath(args[fallback], args[userFileName]) {
+		return invalidFlagError(fallback, args[fallback])
+	}
+	return nil
+}
+
+// loadFallback reads users from the fallback file when the primary one is
+// unreachable or corrupted. The fallback is opened read-only and never created.
+func (s *fileStorage) loadFallback(primaryErr error) ([]User, error) {
+	code := ErrorCodeOf(primaryErr)
+	if len(s.fallback) == 0 || (code != CodeStorageIO && code != CodeInvalidData) {
+		return nil, primaryErr
+	}
+	var users []User
+	err := s.run(func(func() error) error {
+		usersData, err := os.ReadFile(osPath(s.fallback))
+		if err != nil {
+			return newOperationError(CodeStorageIO, fmt.Errorf(openFileErrorMsg, err),
+				map[string]string{userFileName: s.fallback})
+		}
+		users, _, err = decodeUsers(usersData)
+		return withDetail(err, userFileName, s.fallback)
+	})
+	if err != nil {
+		return nil, primaryErr
+	}
+	s.degraded = true
+	fmt.Fprintf(s.warnings, "Warning: degraded mode, reading users from fallback %s: %s\n", s.fallback, primaryErr)
+	return users, nil
+}
+
+func degradedError(s *fileStorage) error {
+	return newOperationError(CodeStorageIO,
+		fmt.Errorf("Users file %s is unavailable and fallback %s is read-only", s.fileName, s.fallback),
+		map[string]string{userFileName: s.fileName, fallback: s.fallback})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFallbackIsReadWhenPrimaryIsCorrupted(t *testing.T) {
+	var buffer bytes.Buffer
+	fallbackFileName := "fallback.json"
+	existingItems := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(fallbackFileName, []byte(existingItems), filePermission)
+	defer os.Remove(fallbackFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "list", "fallback": fallbackFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	if buffer.String() != existingItems {
+		t.Errorf("Expect output to be '%s', but got '%s'", existingItems, buffer.String())
+	}
+}
+
+func TestFallbackIsReadOnly(t *testing.T) {
+	var buffer bytes.Buffer
+	fallbackFileName := "fallback.json"
+	existingItems := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(fallbackFileName, []byte(existingItems), filePermission)
+	defer os.Remove(fallbackFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}", "fallback": fallbackFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeStorageIO {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeStorageIO, ErrorCodeOf(err))
+	}
+
+	bytes, err := ioutil.ReadFile(fallbackFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != existingItems {
+		t.Errorf("Expect fallback content to be '%s', but got '%s'", existingItems, bytes)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const preserveFormat = "preserveFormat"
+
+// rawUsers indexes the users array of a file as it was read so that saving
+// can write unchanged records back byte for byte and keep the whitespace,
+// key order, unknown keys and anything outside the array untouched.
+type rawUsers struct {
+	source     []byte
+	layout     fileLayout
+	arrayStart int
+	arrayEnd   int
+	records    []rawRecord
+	lead       string
+	separators []string
+	trail      string
+}
+
+type rawRecord struct {
+	id    string
+	start int
+	end   int
+	plain string
+}
+
+func indexRawUsers(layout fileLayout, users []User) (*rawUsers, error) {
+	index := &rawUsers{source: layout.source, layout: layout, arrayStart: -1}
+	decoder := json.NewDecoder(bytes.NewReader(layout.source))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == json.Delim('{') {
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			if name == "users" {
+				token, err = decoder.Token()
+				break
+			}
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("users array not found")
+	}
+	index.arrayStart = int(decoder.InputOffset()) - 1
+	for decoder.More() {
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		end := int(decoder.InputOffset())
+		index.records = append(index.records, rawRecord{start: end - len(raw), end: end})
+	}
+	if _, err = decoder.Token(); err != nil {
+		return nil, err
+	}
+	index.arrayEnd = int(decoder.InputOffset())
+	if len(index.records) != len(users) {
+		return nil, fmt.Errorf("users array changed while indexing")
+	}
+	for i := range index.records {
+		plain, err := json.Marshal(users[i])
+		if err != nil {
+			return nil, err
+		}
+		index.records[i].id = users[i].Id
+		index.records[i].plain = string(plain)
+	}
+	index.collectGaps()
+	return index, nil
+}
+
+func (index *rawUsers) collectGaps() {
+	source := string(index.source)
+	if len(index.records) == 0 {
+		index.lead = source[index.arrayStart+1 : index.arrayEnd-1]
+		return
+	}
+	last := index.records[len(index.records)-1]
+	index.lead = source[index.arrayStart+1 : index.records[0].start]
+	index.trail = source[last.end : index.arrayEnd-1]
+	for i := 1; i < len(index.records); i++ {
+		index.separators = append(index.separators, source[index.records[i-1].end:index.records[i].start])
+	}
+}
+
+// splice writes the users back into the original source. plain holds the
+// users as the operation left them and stored the same users ready to be
+// written, which differ when emails are encrypted.
+func (index *rawUsers) splice(plain, stored []User) ([]byte, error) {
+	unused := map[string][]rawRecord{}
+	for _, record := range index.records {
+		unused[record.id] = append(unused[record.id], record)
+	}
+	var array bytes.Buffer
+	array.WriteString("[")
+	array.WriteString(index.lead)
+	for i := range stored {
+		if i > 0 {
+			array.WriteString(index.separator(i - 1))
+		}
+		var original *rawRecord
+		if candidates := unused[stored[i].Id]; len(candidates) > 0 {
+			original = &candidates[0]
+			unused[stored[i].Id] = candidates[1:]
+		}
+		plainData, err := json.Marshal(plain[i])
+		if err != nil {
+			return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		if original != nil && original.plain == string(plainData) {
+			array.Write(index.source[original.start:original.end])
+			continue
+		}
+		recordData, err := index.encodeRecord(original, stored[i])
+		if err != nil {
+			return nil, err
+		}
+		array.Write(recordData)
+	}
+	if len(stored) > 0 {
+		array.WriteString(index.trail)
+	}
+	array.WriteString("]")
+
+	usersData := append([]byte{}, index.source[:index.arrayStart]...)
+	usersData = append(usersData, array.Bytes()...)
+	return append(usersData, index.source[index.arrayEnd:]...), nil
+}
+
+func (index *rawUsers) separator(i int) string {
+	if i < len(index.separators) {
+		return index.separators[i]
+	}
+	if len(index.separators) > 0 {
+		return index.separators[len(index.separators)-1]
+	}
+	return "," + index.lead
+}
+
+// encodeRecord writes a changed or new user. A changed user keeps the key
+// order, unchanged values and unknown keys of its original record.
+func (index *rawUsers) encodeRecord(original *rawRecord, user User) ([]byte, error) {
+	fields, err := userFields(user)
+	if err != nil {
+		return nil, err
+	}
+	userData, _ := json.Marshal(user)
+	names := objectKeys(userData)
+	var members []string
+	if original != nil {
+		originalData := index.source[original.start:original.end]
+		var originalFields map[string]json.RawMessage
+		err = json.Unmarshal(originalData, &originalFields)
+		if err != nil {
+			return nil, newOperationError(CodeInvalidData, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+		known := userJSONNames()
+		written := map[string]bool{}
+		for _, name := range objectKeys(originalData) {
+			value, ok := fields[name]
+			switch {
+			case ok && sameJSON(value, originalFields[name]):
+				value = originalFields[name]
+			case !ok && !known[name]:
+				value = originalFields[name]
+			case !ok:
+				continue
+			}
+			members = append(members, jsonMember(name, value))
+			written[name] = true
+		}
+		var rest []string
+		for _, name := range names {
+			if !written[name] {
+				rest = append(rest, name)
+			}
+		}
+		names = rest
+	}
+	for _, name := range names {
+		members = append(members, jsonMember(name, fields[name]))
+	}
+	recordData := []byte("{" + strings.Join(members, ",") + "}")
+	prefix, indent, multiline := index.recordIndent()
+	if !multiline {
+		return recordData, nil
+	}
+	var indented bytes.Buffer
+	err = json.Indent(&indented, recordData, prefix, indent)
+	if err != nil {
+		return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	return indented.Bytes(), nil
+}
+
+// recordIndent guesses the indentation of multi-line records from the first
+// record of the file.
+func (index *rawUsers) recordIndent() (string, string, bool) {
+	if len(index.records) == 0 {
+		return "", "", false
+	}
+	first := string(index.source[index.records[0].start:index.records[0].end])
+	lineStart := bytes.LastIndexByte(index.source[:index.records[0].start], '\n') + 1
+	prefix := string(index.source[lineStart:index.records[0].start])
+	if strings.TrimSpace(prefix) != "" {
+		prefix = ""
+	}
+	newline := strings.IndexByte(first, '\n')
+	if newline < 0 {
+		return "", "", false
+	}
+	line := first[newline+1:]
+	inner := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	indent := strings.TrimPrefix(inner, prefix)
+	if len(indent) == 0 {
+		indent = "  "
+	}
+	return prefix, indent, true
+}
+
+func objectKeys(objectData []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(objectData))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+	var names []string
+	for decoder.More() {
+		name, err := decoder.Token()
+		if err != nil {
+			return names
+		}
+		var skipped json.RawMessage
+		if decoder.Decode(&skipped) != nil {
+			return names
+		}
+		names = append(names, name.(string))
+	}
+	return names
+}
+
+func userJSONNames() map[string]bool {
+	names := map[string]bool{}
+	userType := reflect.TypeOf(User{})
+	for i := 0; i < userType.NumField(); i++ {
+		name, _, _ := strings.Cut(userType.Field(i).Tag.Get("json"), ",")
+		names[name] = true
+	}
+	return names
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	var aCompact, bCompact bytes.Buffer
+	if json.Compact(&aCompact, a) != nil || json.Compact(&bCompact, b) != nil {
+		return false
+	}
+	return bytes.Equal(aCompact.Bytes(), bCompact.Bytes())
+}
+
+func jsonMember(name string, value json.RawMessage) string {
+	nameData, _ := json.Marshal(name)
+	return string(nameData) + ":" + string(value)
+}
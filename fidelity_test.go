@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPreserveFormatKeepsUnchangedRecords(t *testing.T) {
+	var buffer bytes.Buffer
+
+	fileContent := "{\"source\": \"crm\", \"schemaVersion\": 2,\n \"users\": [ {\"email\":\"test1@test.com\", \"id\":\"1\",  \"age\":22, \"team\":\"a\"},\n\t{ \"id\" : \"2\", \"email\" : \"test2@test.com\", \"age\" : 31 } ],\n \"exported\": 3}\n"
+	err := ioutil.WriteFile(fileName, []byte(fileContent), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "patch", "id": "1", "item": "{\"age\":23}", "fileName": fileName, "preserveFormat": "true"}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "{\"source\": \"crm\", \"schemaVersion\": 2,\n \"users\": [ {\"email\":\"test1@test.com\",\"id\":\"1\",\"age\":23,\"team\":\"a\"},\n\t{ \"id\" : \"2\", \"email\" : \"test2@test.com\", \"age\" : 31 } ],\n \"exported\": 3}\n"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestPreserveFormatIndentsAddedRecords(t *testing.T) {
+	var buffer bytes.Buffer
+
+	fileContent := "[\n    {\n        \"id\": \"1\",\n        \"email\": \"test1@test.com\",\n        \"age\": 22\n    }\n]"
+	err := ioutil.WriteFile(fileName, []byte(fileContent), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}", "fileName": fileName, "preserveFormat": "true"}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[\n    {\n        \"id\": \"1\",\n        \"email\": \"test1@test.com\",\n        \"age\": 22\n    },\n    {\n        \"id\": \"2\",\n        \"email\": \"test2@test.com\",\n        \"age\": 31\n    }\n]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const filter = "filter"
+
+type predicate func(user User) bool
+
+type filterParser struct {
+	expression string
+	tokens     []string
+	pos        int
+}
+
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"contains": true, "startsWith": true, "endsWith": true,
+}
+
+func parseFilter(expression string) (predicate, error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{expression: expression, tokens: tokens}
+	match, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos != len(tokens) {
+		return nil, parser.syntaxError()
+	}
+	return match, nil
+}
+
+func filterUsers(users []User, match predicate) []User {
+	filtered := []User{}
+	for _, user := range users {
+		if match(user) {
+			filtered = append(filtered, user)
+		}
+	}
+	return filtered
+}
+
+func tokenizeFilter(expression string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, filterSyntaxError(expression)
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case strings.ContainsRune("=!<>", r):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, filterSyntaxError(expression)
+			}
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("=!<>'\"&|", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func (p *filterParser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(user User) bool { return l(user) || right(user) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (predicate, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and", "&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(user User) bool { return l(user) && right(user) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseComparison() (predicate, error) {
+	if p.pos+3 > len(p.tokens) {
+		return nil, p.syntaxError()
+	}
+	field, operator, value := p.tokens[p.pos], p.tokens[p.pos+1], unquote(p.tokens[p.pos+2])
+	if !comparisonOperators[operator] {
+		return nil, p.syntaxError()
+	}
+	if _, ok := userFieldValue(User{}, field); !ok {
+		return nil, newOperationError(CodeValidation, fmt.Errorf("Unknown field %s in filter", field),
+			map[string]string{filter: p.expression})
+	}
+	p.pos += 3
+	return func(user User) bool {
+		actual, _ := userFieldValue(user, field)
+		return compareValues(actual, operator, value)
+	}, nil
+}
+
+func (p *filterParser) accept(keywords ...string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	for _, keyword := range keywords {
+		if p.tokens[p.pos] == keyword {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *filterParser) syntaxError() error {
+	return filterSyntaxError(p.expression)
+}
+
+func filterSyntaxError(expression string) error {
+	return newOperationError(CodeValidation, fmt.Errorf("Invalid filter expression %s", expression),
+		map[string]string{filter: expression})
+}
+
+func unquote(token string) string {
+	if len(token) >= 2 && (token[0] == '\'' || token[0] == '"') && token[len(token)-1] == token[0] {
+		return token[1 : len(token)-1]
+	}
+	return token
+}
+
+func userFieldValue(user User, field string) (string, bool) {
+	switch field {
+	case "id":
+		return user.Id, true
+	case "email":
+		return user.Email, true
+	case "domain":
+		return emailDomain(user.Email), true
+	case "age":
+		return strconv.FormatUint(uint64(user.Age), 10), true
+	case "phone":
+		return user.Phone, true
+	case "dob":
+		return user.Dob, true
+	case "status":
+		return user.Status, true
+	case "country":
+		if user.Address == nil {
+			return "", true
+		}
+		return user.Address.Country, true
+	case "city":
+		if user.Address == nil {
+			return "", true
+		}
+		return user.Address.City, true
+	}
+	if strings.HasPrefix(field, "meta.") && len(field) > len("meta.") {
+		return user.Metadata[strings.TrimPrefix(field, "meta.")], true
+	}
+	return "", false
+}
+
+func emailDomain(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(address[at+1:])
+}
+
+func compareValues(actual, operator, expected string) bool {
+	switch operator {
+	case "contains":
+		return strings.Contains(foldText(actual), foldText(expected))
+	case "startsWith":
+		return strings.HasPrefix(foldText(actual), foldText(expected))
+	case "endsWith":
+		return strings.HasSuffix(foldText(actual), foldText(expected))
+	}
+	order := strings.Compare(actual, expected)
+	actualNumber, errActual := strconv.ParseFloat(actual, 64)
+	expectedNumber, errExpected := strconv.ParseFloat(expected, 64)
+	if errActual == nil && errExpected == nil {
+		switch {
+		case actualNumber < expectedNumber:
+			order = -1
+		case actualNumber > expectedNumber:
+			order = 1
+		default:
+			order = 0
+		}
+	}
+	switch operator {
+	case "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	users := []User{
+		{Id: "1", Email: "a@old-domain.com", Age: 17, Metadata: map[string]string{"team": "sre"}},
+		{Id: "2", Email: "b@new-domain.com", Age: 30, Metadata: map[string]string{"team": "dev"}},
+		{Id: "3", Email: "c@old-domain.com", Age: 45},
+	}
+	cases := map[string]int{
+		"meta.team == 'sre'": 1,
+		"age<18":             1,
+		"age >= 18 and email endsWith @old-domain.com": 1,
+		"age < 18 || meta.team == \"dev\"":             2,
+		"email contains DOMAIN":                        3,
+	}
+	for expression, expected := range cases {
+		match, err := parseFilter(expression)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if count := len(filterUsers(users, match)); count != expected {
+			t.Errorf("Expect '%s' to match %d users, but got %d", expression, expected, count)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expression := range []string{"age", "age ~ 3", "salary > 3", "email == 'open"} {
+		_, err := parseFilter(expression)
+		if code := ErrorCodeOf(err); code != CodeValidation {
+			t.Errorf("Expect '%s' to fail with '%s', but got '%s'", expression, CodeValidation, code)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const files = "files"
+
+type fileResult struct {
+	File   string          `json:"file"`
+	Output json.RawMessage `json:"output,omitempty"`
+	Error  *fileError      `json:"error,omitempty"`
+}
+
+type fileError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// forEachFile runs the operation against every users file matching the -files
+// glob with a pool of -concurrency workers and writes one result per file.
+// Messages and warnings of each file follow on their own streams, in file
+// order, messages prefixed with the file name.
+func forEachFile(args Arguments, writer, diagnostics, warnings io.Writer) error {
+	workers, err := positiveIntArg(args, concurrency, defaultConcurrency)
+	if err != nil {
+		return err
+	}
+	matches, err := filepath.Glob(args[files])
+	if err != nil {
+		return invalidFlagError(files, args[files])
+	}
+	if len(matches) == 0 {
+		return newOperationError(CodeNotFound, fmt.Errorf("No users files match %s", args[files]),
+			map[string]string{files: args[files]})
+	}
+	sort.Strings(matches)
+
+	results := make([]fileResult, len(matches))
+	messages := make([]bytes.Buffer, len(matches))
+	fileWarnings := make([]bytes.Buffer, len(matches))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = performOnFile(args, matches[i], &messages[i], &fileWarnings[i])
+			}
+		}()
+	}
+	for i := range matches {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	for i := range matches {
+		if messages[i].Len() > 0 {
+			fmt.Fprintf(diagnostics, "%s: %s\n", matches[i], messages[i].String())
+		}
+		warnings.Write(fileWarnings[i].Bytes())
+	}
+
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	failed := 0
+	var firstErr *fileError
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = result.Error
+			}
+		}
+	}
+	if failed > 0 {
+		return newOperationError(firstErr.Code, fmt.Errorf("Operation %s failed for %d of %d files", args[operation], failed, len(matches)),
+			map[string]string{files: args[files]})
+	}
+	return nil
+}
+
+func performOnFile(args Arguments, fileNameArg string, diagnostics, warnings io.Writer) fileResult {
+	fileArgs := make(Arguments, len(args))
+	for key, value := range args {
+		fileArgs[key] = value
+	}
+	delete(fileArgs, files)
+	fileArgs[userFileName] = fileNameArg
+
+	var output bytes.Buffer
+	result := fileResult{File: fileNameArg}
+	err := perform(fileArgs, &output, diagnostics, warnings)
+	if err != nil {
+		result.Error = &fileError{Code: ErrorCodeOf(err), Message: err.Error()}
+	}
+	if output.Len() > 0 {
+		if json.Valid(output.Bytes()) {
+			result.Output = output.Bytes()
+		} else {
+			result.Output, _ = json.Marshal(output.String())
+		}
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestOperationOnManyFiles(t *testing.T) {
+	var buffer bytes.Buffer
+	tenantFiles := map[string]string{
+		"tenant-a.json": "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17}]",
+		"tenant-b.json": "[{\"id\":\"1\",\"email\":\"b@test.com\",\"age\":40}]",
+		"tenant-c.json": "[{\"id\":",
+	}
+	for name, content := range tenantFiles {
+		err := ioutil.WriteFile(name, []byte(content), filePermission)
+		defer os.Remove(name)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	args := Arguments{"operation": "count", "filter": "age >= 18", "files": "tenant-*.json"}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeInvalidData {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeInvalidData, ErrorCodeOf(err))
+	}
+
+	expectedOutput := "[{\"file\":\"tenant-a.json\",\"output\":1},{\"file\":\"tenant-b.json\",\"output\":1}," +
+		"{\"file\":\"tenant-c.json\",\"error\":{\"code\":\"INVALID_DATA\",\"message\":\"Error to unmarshal a user defined with JSON: unexpected end of JSON input\"}}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestOperationOnManyFilesKeepsMessagesApart(t *testing.T) {
+	var data, diagnostics bytes.Buffer
+	tenantFiles := map[string]string{
+		"tenant-a.json": "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]",
+		"tenant-b.json": "[]",
+	}
+	for name, content := range tenantFiles {
+		err := ioutil.WriteFile(name, []byte(content), filePermission)
+		defer os.Remove(name)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}", "files": "tenant-*.json"}
+	err := PerformTo(args, &data, &diagnostics)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"file\":\"tenant-a.json\"},{\"file\":\"tenant-b.json\"}]"
+	if data.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, data.String())
+	}
+	expectedDiagnostics := "tenant-a.json: Item with id 1 already exists\n"
+	if diagnostics.String() != expectedDiagnostics {
+		t.Errorf("Expect diagnostics to be '%s', but got '%s'", expectedDiagnostics, diagnostics.String())
+	}
+}
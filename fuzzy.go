@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const (
+	fuzzyFindOp     = "fuzzyFind"
+	email           = "email"
+	distance        = "distance"
+	defaultDistance = 2
+)
+
+func fuzzyFindUsers(emailArg, distanceArg string, storage *fileStorage, writer io.Writer) error {
+	maxDistance, err := parseDistance(distanceArg)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	target := foldText(emailArg)
+	distances := map[string]int{}
+	found := []User{}
+	for _, user := range users {
+		d := levenshtein(target, foldText(user.Email))
+		if d <= maxDistance {
+			distances[user.Id] = d
+			found = append(found, user)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return distances[found[i].Id] < distances[found[j].Id]
+	})
+	usersData, err := json.Marshal(found)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(usersData)
+	return nil
+}
+
+func parseDistance(distanceArg string) (int, error) {
+	if len(distanceArg) == 0 {
+		return defaultDistance, nil
+	}
+	maxDistance, err := strconv.Atoi(distanceArg)
+	if err != nil || maxDistance < 0 {
+		return 0, invalidFlagError(distance, distanceArg)
+	}
+	return maxDistance, nil
+}
+
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j] + 1
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+			if previous[j-1]+cost < current[j] {
+				current[j] = previous[j-1] + cost
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	cases := map[[2]string]int{
+		{"gmail.com", "gmial.com"}: 2,
+		{"kitten", "sitting"}:      3,
+		{"", "abc"}:                3,
+		{"same", "same"}:           0,
+	}
+	for pair, expected := range cases {
+		if d := levenshtein(pair[0], pair[1]); d != expected {
+			t.Errorf("Expect distance between %s and %s to be %d, but got %d", pair[0], pair[1], expected, d)
+		}
+	}
+}
+
+func TestFuzzyFindOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"john@gmial.com\",\"age\":34},{\"id\":\"2\",\"email\":\"jane@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"john@gmial.com\",\"age\":34}]"
+	args := Arguments{"operation": "fuzzyFind", "email": "John@gmail.com", "distance": "2", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
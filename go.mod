@@ -1,3 +1,8 @@
 module golang-united-school-homework-8
 
 go 1.18
+
+require (
+	golang.org/x/net v0.19.0
+	golang.org/x/text v0.14.0
+)
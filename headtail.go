@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io"
+)
+
+const (
+	headOp        = "head"
+	tailOp        = "tail"
+	defaultNumber = 10
+)
+
+// headOrTailUsers lists only the first or the last -n users matching the
+// filter, ten by default.
+func headOrTailUsers(operationArg string, args Arguments, match predicate, fields []computedField, storage *fileStorage, writer io.Writer) error {
+	if err := validateUsersFormat(args[format]); err != nil {
+		return err
+	}
+	n, err := positiveIntArg(args, number, defaultNumber)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	if match != nil {
+		users = filterUsers(users, match)
+	}
+	if n < len(users) {
+		if operationArg == headOp {
+			users = users[:n]
+		} else {
+			users = users[len(users)-n:]
+		}
+	}
+	return writeUsersAs(args[format], args[templateSource], users, fields, writer)
+}
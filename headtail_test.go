@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestHeadOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":21},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "head", "n": "2", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":21},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestTailOperationWithFilter(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":21},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "tail", "n": "1", "filter": "age < 23", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
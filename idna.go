@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// idnEmail returns the address with its internationalized domain converted
+// to its ASCII form, or an empty string when the domain is already ASCII.
+func idnEmail(address string) (string, error) {
+	at := strings.LastIndex(address, "@")
+	if at < 0 || isASCII(address[at+1:]) {
+		return "", nil
+	}
+	domain, err := idna.Lookup.ToASCII(address[at+1:])
+	if err != nil {
+		return "", newOperationError(CodeValidation, fmt.Errorf("Invalid email domain %s: %w", address[at+1:], err),
+			map[string]string{"email": address})
+	}
+	return address[:at+1] + domain, nil
+}
+
+func asciiEmail(address string) string {
+	encoded, err := idnEmail(address)
+	if err != nil || len(encoded) == 0 {
+		return address
+	}
+	return encoded
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
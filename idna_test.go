@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAsciiEmail(t *testing.T) {
+	cases := map[string]string{
+		"user@bücher.de":         "user@xn--bcher-kva.de",
+		"user@München.example":   "user@xn--mnchen-3ya.example",
+		"пользователь@пример.рф": "пользователь@xn--e1afmkfd.xn--p1ai",
+		"plain@test.com":         "plain@test.com",
+	}
+	for address, expected := range cases {
+		if encoded := asciiEmail(address); encoded != expected {
+			t.Errorf("Expect %s to be encoded as %s, but got %s", address, expected, encoded)
+		}
+	}
+}
+
+func TestIdnEmailOnlyForInternationalDomains(t *testing.T) {
+	encoded, err := idnEmail("Bob@Example.COM")
+	if err != nil || encoded != "" {
+		t.Errorf("Expect no ascii form for an ascii domain, but got '%s' (%v)", encoded, err)
+	}
+	_, err = idnEmail("user@xn--a.deé")
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
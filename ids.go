@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	idType             = "idType"
+	idPattern          = "idPattern"
+	scanIdsOp          = "scanIds"
+	uuidIdType         = "uuid"
+	ulidIdType         = "ulid"
+	nanoidIdType       = "nanoid"
+	intIdType          = "int"
+	idCollisionRetries = 5
+	crockfordAlphabet  = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	nanoidAlphabet     = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	nanoidLength       = 21
+)
+
+var idFormats = map[string]*regexp.Regexp{
+	uuidIdType:   regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+	ulidIdType:   regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`),
+	nanoidIdType: regexp.MustCompile(`^[A-Za-z0-9_-]{21}$`),
+	intIdType:    regexp.MustCompile(`^[1-9][0-9]*$`),
+}
+
+func validateIdTypeArg(idTypeArg string) error {
+	if _, ok := idFormats[idTypeArg]; len(idTypeArg) > 0 && !ok {
+		return invalidFlagError(idType, idTypeArg)
+	}
+	return nil
+}
+
+func validateIdFormat(userId, idTypeArg string) error {
+	format, ok := idFormats[idTypeArg]
+	if !ok || format.MatchString(userId) {
+		return nil
+	}
+	return newOperationError(CodeValidation, fmt.Errorf("Id %s is not a valid %s", userId, idTypeArg),
+		map[string]string{id: userId, idType: idTypeArg})
+}
+
+type randomIDGenerator struct{}
+
+func generateId(idTypeArg string, users []User) (string, error) {
+	return DefaultIDGenerator.NewID(idTypeArg, users)
+}
+
+func (randomIDGenerator) NewID(idTypeArg string, users []User) (string, error) {
+	taken := make(map[string]bool, len(users))
+	maxInt := 0
+	for _, user := range users {
+		taken[user.Id] = true
+		if value, err := strconv.Atoi(user.Id); err == nil && value > maxInt {
+			maxInt = value
+		}
+	}
+	if idTypeArg == intIdType {
+		return strconv.Itoa(maxInt + 1), nil
+	}
+	for attempt := 0; attempt < idCollisionRetries; attempt++ {
+		var userId string
+		var err error
+		switch idTypeArg {
+		case uuidIdType:
+			userId, err = newUUID()
+		case ulidIdType:
+			userId, err = newULID(now())
+		default:
+			userId, err = newNanoid()
+		}
+		if err != nil {
+			return "", newOperationError(CodeInternal, fmt.Errorf("Error while generating id: %w", err), nil)
+		}
+		if !taken[userId] {
+			return userId, nil
+		}
+	}
+	return "", newOperationError(CodeConflict, errors.New("Unable to generate a unique id"),
+		map[string]string{idType: idTypeArg})
+}
+
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func newULID(now time.Time) (string, error) {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(now.UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	encoded := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		encoded[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(encoded), nil
+}
+
+func newNanoid() (string, error) {
+	b := make([]byte, nanoidLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = nanoidAlphabet[b[i]&0x3f]
+	}
+	return string(b), nil
+}
+
+func compileIdPattern(idPatternArg string) (*regexp.Regexp, error) {
+	if len(idPatternArg) == 0 {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile("^(?:" + idPatternArg + ")$")
+	if err != nil {
+		return nil, invalidFlagError(idPattern, idPatternArg)
+	}
+	return pattern, nil
+}
+
+func validateIdPattern(userId string, pattern *regexp.Regexp) error {
+	if pattern == nil || pattern.MatchString(userId) {
+		return nil
+	}
+	return newOperationError(CodeValidation, fmt.Errorf("Id %s does not match pattern %s", userId, pattern),
+		map[string]string{id: userId, idPattern: pattern.String()})
+}
+
+func scanIds(pattern *regexp.Regexp, storage *fileStorage, writer io.Writer) error {
+	if pattern == nil {
+		return missingFlagError(idPattern)
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	nonConforming := []string{}
+	for _, user := range users {
+		if !pattern.MatchString(user.Id) {
+			nonConforming = append(nonConforming, user.Id)
+		}
+	}
+	idsData, err := json.Marshal(nonConforming)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(idsData)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestGenerateIdFormats(t *testing.T) {
+	for _, idTypeArg := range []string{"uuid", "ulid", "nanoid", "int"} {
+		userId, err := generateId(idTypeArg, []User{{Id: "7"}})
+		if err != nil {
+			t.Error(err)
+		}
+		if err = validateIdFormat(userId, idTypeArg); err != nil {
+			t.Errorf("Expect generated %s id to be valid, but got %s", idTypeArg, userId)
+		}
+	}
+}
+
+func TestGenerateIntIdContinuesSequence(t *testing.T) {
+	userId, err := generateId("int", []User{{Id: "3"}, {Id: "12"}, {Id: "abc"}})
+	if err != nil {
+		t.Error(err)
+	}
+	if userId != "13" {
+		t.Errorf("Expect generated id to be '13', but got '%s'", userId)
+	}
+}
+
+func TestScanIdsOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"EMP-000001\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"42\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[\"42\"]"
+	args := Arguments{"operation": "scanIds", "idPattern": "EMP-\\d{6}", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestAddingOperationIdPatternMismatch(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"EMP-1\",\"email\":\"test@test.com\",\"age\":34}", "idPattern": "EMP-\\d{6}", "fileName": fileName}
+
+	err := Perform(args, &buffer)
+
+	if code := ErrorCodeOf(err); code != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, code)
+	}
+}
+
+type sequenceIDGenerator struct {
+	next int
+}
+
+func (g *sequenceIDGenerator) NewID(idType string, existing []User) (string, error) {
+	g.next++
+	return "seq-" + strconv.Itoa(g.next), nil
+}
+
+func TestAddingOperationUsesIDGenerator(t *testing.T) {
+	var buffer bytes.Buffer
+	DefaultIDGenerator = &sequenceIDGenerator{}
+	defer func() { DefaultIDGenerator = randomIDGenerator{} }()
+	defer os.Remove(fileName)
+
+	expectedFileContent := "[{\"id\":\"seq-1\",\"email\":\"test@test.com\",\"age\":34}]"
+	args := Arguments{"operation": "add", "item": "{\"email\":\"test@test.com\",\"age\":34}", "idType": "nanoid", "fileName": fileName}
+
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"time"
+)
+
+const (
+	inviteOp         = "invite"
+	acceptInviteOp   = "acceptInvite"
+	token            = "token"
+	inviteTtl        = "inviteTtl"
+	pendingStatus    = "pending"
+	defaultInviteTtl = 72 * time.Hour
+)
+
+type Invite struct {
+	TokenHash string `json:"tokenHash"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type inviteResult struct {
+	Id        string `json:"id"`
+	Email     string `json:"email"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+func inviteUser(args Arguments, pattern *regexp.Regexp, storage *fileStorage, writer io.Writer) error {
+	notifications, err := newNotifier(args)
+	if err != nil {
+		return err
+	}
+	ttl := defaultInviteTtl
+	if value := args[inviteTtl]; len(value) > 0 {
+		ttl, err = time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return invalidFlagError(inviteTtl, value)
+		}
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	invited := User{Id: args[id], Email: args[email], Status: pendingStatus}
+	idTypeArg := args[idType]
+	if len(invited.Id) == 0 && len(idTypeArg) == 0 {
+		idTypeArg = uuidIdType
+	}
+	err = prepareUser(&invited, users, idTypeArg, args[defaultCountry], pattern)
+	if err != nil {
+		return err
+	}
+	if address, err := mail.ParseAddress(invited.Email); err != nil || address.Address != invited.Email {
+		return invalidFlagError(email, args[email])
+	}
+	for _, user := range users {
+		if user.Id == invited.Id {
+			return newOperationError(CodeConflict, fmt.Errorf("Item with id %s already exists", invited.Id),
+				map[string]string{id: invited.Id})
+		}
+	}
+	rawToken := make([]byte, 16)
+	if _, err = rand.Read(rawToken); err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf("Error while generating invite token: %w", err), nil)
+	}
+	inviteToken := hex.EncodeToString(rawToken)
+	expiresAt := now().Add(ttl).UTC().Format(time.RFC3339)
+	invited.Invite = &Invite{TokenHash: hashToken(inviteToken), ExpiresAt: expiresAt}
+	users = append(users, invited)
+	err = storage.save(users)
+	if err != nil {
+		return err
+	}
+	result := inviteResult{Id: invited.Id, Email: invited.Email, Token: inviteToken, ExpiresAt: expiresAt}
+	resultData, err := json.Marshal(result)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultData)
+	// The invite is saved and its token printed, so a failed mail only warns.
+	if err = notifications.notifyInvite(result); err != nil {
+		fmt.Fprintf(storage.warnings, "Warning: invite notification was not sent: %s\n", err)
+	}
+	return nil
+}
+
+func acceptInvite(tokenArg, itemArg, countryArg string, storage *fileStorage, writer io.Writer) error {
+	var details User
+	if len(itemArg) > 0 {
+		err := json.Unmarshal([]byte(itemArg), &details)
+		if err != nil {
+			return newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+	}
+	err := normalizeUser(&details, countryArg)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	tokenHash := hashToken(tokenArg)
+	for i, user := range users {
+		if user.Status != pendingStatus || user.Invite == nil || user.Invite.TokenHash != tokenHash {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, user.Invite.ExpiresAt)
+		if err != nil || now().After(expiresAt) {
+			return newOperationError(CodeValidation, errors.New("Invite token has expired"), map[string]string{id: user.Id})
+		}
+		details.Id = user.Id
+		accepted := mergeUsers(user, details)
+		accepted.Status = ""
+		accepted.Invite = nil
+		users[i] = accepted
+		err = storage.save(users)
+		if err != nil {
+			return err
+		}
+		userData, err := json.Marshal(storage.shown([]User{accepted})[0])
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		writer.Write(userData)
+		return nil
+	}
+	return newOperationError(CodeNotFound, errors.New("Invite token not found"), nil)
+}
+
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestInviteAndAccept(t *testing.T) {
+	var buffer bytes.Buffer
+	DefaultClock = FixedClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	defer func() { DefaultClock = ClockFunc(time.Now) }()
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "invite", "id": "7", "email": "new@test.com", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var invite inviteResult
+	err = json.Unmarshal(buffer.Bytes(), &invite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invite.ExpiresAt != "2024-05-04T12:00:00Z" {
+		t.Errorf("Expect invite to expire at 2024-05-04T12:00:00Z, but got %s", invite.ExpiresAt)
+	}
+
+	buffer.Reset()
+	expectedOutput := "{\"id\":\"7\",\"email\":\"new@test.com\",\"age\":29}"
+	args = Arguments{"operation": "acceptInvite", "token": invite.Token, "item": "{\"age\":29}", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	err = Perform(args, &buffer)
+	if code := ErrorCodeOf(err); code != CodeNotFound {
+		t.Errorf("Expect reused token to fail with '%s', but got '%s'", CodeNotFound, code)
+	}
+}
+
+func TestAcceptExpiredInvite(t *testing.T) {
+	var buffer bytes.Buffer
+	DefaultClock = FixedClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	defer func() { DefaultClock = ClockFunc(time.Now) }()
+	defer os.Remove(fileName)
+
+	err := Perform(Arguments{"operation": "invite", "email": "new@test.com", "inviteTtl": "1h", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var invite inviteResult
+	err = json.Unmarshal(buffer.Bytes(), &invite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	DefaultClock = FixedClock(time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC))
+	err = Perform(Arguments{"operation": "acceptInvite", "token": invite.Token, "fileName": fileName}, &buffer)
+
+	if code := ErrorCodeOf(err); code != CodeValidation {
+		t.Errorf("Expect expired token to fail with '%s', but got '%s'", CodeValidation, code)
+	}
+}
+
+func TestInviteValidatesUser(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	cases := []Arguments{
+		{"operation": "invite", "email": "not an email", "fileName": fileName},
+		{"operation": "invite", "email": "test@test.com", "id": "1", "idPattern": "EMP-\\d{6}", "fileName": fileName},
+	}
+	for _, args := range cases {
+		err := Perform(args, &buffer)
+		if ErrorCodeOf(err) != CodeValidation {
+			t.Errorf("Expect error code of %v to be '%s', but got '%s'", args, CodeValidation, ErrorCodeOf(err))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+const (
+	mailmergeFormat = "mailmerge"
+	templateFile    = "template"
+)
+
+type documentTemplate interface {
+	Execute(writer io.Writer, data interface{}) error
+}
+
+func exportMailMerge(args Arguments, storage *fileStorage, writer io.Writer) error {
+	templateArg := args[templateFile]
+	if len(templateArg) == 0 {
+		return missingFlagError(templateFile)
+	}
+	document, extension, err := loadDocumentTemplate(templateArg)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	outputArg := args[output]
+	if strings.HasSuffix(outputArg, ".zip") {
+		return writeMailMergeZip(users, document, extension, outputArg)
+	}
+	var rendered bytes.Buffer
+	for _, user := range users {
+		err = renderDocument(document, user, &rendered)
+		if err != nil {
+			return err
+		}
+	}
+	if len(outputArg) == 0 {
+		writer.Write(rendered.Bytes())
+		return nil
+	}
+	err = os.WriteFile(osPath(outputArg), rendered.Bytes(), 0644)
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing mail merge output: %w", err),
+			map[string]string{output: outputArg})
+	}
+	return nil
+}
+
+func loadDocumentTemplate(templateArg string) (documentTemplate, string, error) {
+	data, err := os.ReadFile(osPath(templateArg))
+	if err != nil {
+		return nil, "", newOperationError(CodeStorageIO, fmt.Errorf("Error while reading template: %w", err),
+			map[string]string{templateFile: templateArg})
+	}
+	extension := filepath.Ext(strings.TrimSuffix(templateArg, ".tmpl"))
+	if extension == ".html" || extension == ".htm" {
+		document, err := htmltemplate.New(filepath.Base(templateArg)).Parse(string(data))
+		if err != nil {
+			return nil, "", templateParseError(templateArg, err)
+		}
+		return document, ".html", nil
+	}
+	document, err := texttemplate.New(filepath.Base(templateArg)).Parse(string(data))
+	if err != nil {
+		return nil, "", templateParseError(templateArg, err)
+	}
+	return document, ".txt", nil
+}
+
+func templateParseError(templateArg string, err error) error {
+	return newOperationError(CodeValidation, fmt.Errorf("Error while parsing template: %w", err),
+		map[string]string{templateFile: templateArg})
+}
+
+func renderDocument(document documentTemplate, user User, writer io.Writer) error {
+	err := document.Execute(writer, user)
+	if err != nil {
+		return newOperationError(CodeValidation, fmt.Errorf("Error while rendering template for user %s: %w", user.Id, err),
+			map[string]string{id: user.Id})
+	}
+	return nil
+}
+
+// zipEntryName names the document of a user after its id, or after its
+// position when the id could point outside the archive.
+func zipEntryName(userId string, index int) string {
+	if len(userId) == 0 || userId == "." || userId == ".." || strings.ContainsAny(userId, "/\\:") {
+		return fmt.Sprintf("user-%d", index+1)
+	}
+	return userId
+}
+
+func writeMailMergeZip(users []User, document documentTemplate, extension, outputArg string) error {
+	file, err := os.OpenFile(osPath(outputArg), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while opening export file: %w", err),
+			map[string]string{output: outputArg})
+	}
+	defer file.Close()
+	archive := zip.NewWriter(file)
+	for i, user := range users {
+		entry, err := archive.Create(zipEntryName(user.Id, i) + extension)
+		if err != nil {
+			return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing mail merge archive: %w", err),
+				map[string]string{output: outputArg})
+		}
+		err = renderDocument(document, user, entry)
+		if err != nil {
+			return err
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing mail merge archive: %w", err),
+			map[string]string{output: outputArg})
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestExportMailMerge(t *testing.T) {
+	var buffer bytes.Buffer
+	templateName := "letter.tmpl"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"a@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"b@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(templateName, []byte("Dear {{.Email}}, you are {{.Age}}.\n"), filePermission)
+	defer os.Remove(templateName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "Dear a@test.com, you are 34.\nDear b@test.com, you are 31.\n"
+	args := Arguments{"operation": "export", "format": "mailmerge", "template": templateName, "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestExportMailMergeZip(t *testing.T) {
+	var buffer bytes.Buffer
+	templateName := "letter.html.tmpl"
+	archiveName := "letters.zip"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"<a>@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(templateName, []byte("<p>{{.Email}}</p>"), filePermission)
+	defer os.Remove(templateName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(archiveName)
+
+	args := Arguments{"operation": "export", "format": "mailmerge", "template": templateName, "output": archiveName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.OpenReader(archiveName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if len(archive.File) != 1 || archive.File[0].Name != "1.html" {
+		t.Fatalf("Expect archive to contain 1.html, but got %v", archive.File)
+	}
+	entry, err := archive.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer entry.Close()
+	content, err := ioutil.ReadAll(entry)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(content) != "<p>&lt;a&gt;@test.com</p>" {
+		t.Errorf("Expect HTML to be escaped, but got %s", content)
+	}
+}
+
+func TestZipEntryName(t *testing.T) {
+	cases := map[string]string{
+		"1":       "1",
+		"EMP-001": "EMP-001",
+		"../../x": "user-3",
+		"a/b":     "user-3",
+		"a\\b":    "user-3",
+		"..":      "user-3",
+		"":        "user-3",
+	}
+	for userId, expected := range cases {
+		if name := zipEntryName(userId, 2); name != expected {
+			t.Errorf("Expect entry of %s to be named %s, but got %s", userId, expected, name)
+		}
+	}
+}
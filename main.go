@@ -2,11 +2,14 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -16,76 +19,517 @@ const (
 	operation            = "operation"
 	addOp                = "add"
 	findByIdOp           = "findById"
+	findByEmailOp        = "findByEmail"
 	removeOp             = "remove"
 	listOp               = "list"
+	updateOp             = "update"
+	upsertOp             = "upsert"
+	countOp              = "count"
+	clearOp              = "clear"
+	removeWhereOp        = "removeWhere"
+	where                = "where"
+	confirm              = "confirm"
 	userNotFoundMsg      = "Item with id %s not found"
 	marshalingErrorMsg   = "Error while marshaling users to json file: %w"
 	unmarshalingErrorMsg = "Error to unmarshal a user defined with JSON: %w"
 	openFileErrorMsg     = "Error while opening file with users: %w"
+	idsFile              = "idsFile"
+	onConflict           = "onConflict"
+	skipOnConflict       = "skip"
+	overwriteOnConflict  = "overwrite"
+	failOnConflict       = "fail"
+	mergeOnConflict      = "merge"
+	addedResult          = "added"
+	skippedResult        = "skipped"
+	overwrittenResult    = "overwritten"
+	mergedResult         = "merged"
+	removedResult        = "removed"
+	notFoundResult       = "not found"
+	errorFormat          = "errorFormat"
+	textErrorFormat      = "text"
+	jsonErrorFormat      = "json"
 )
 
 type Arguments map[string]string
 type User struct {
-	Id    string `json:"id"`
-	Email string `json:"email"`
-	Age   uint   `json:"age"`
+	Id         string            `json:"id"`
+	Email      string            `json:"email"`
+	EmailAscii string            `json:"emailAscii,omitempty"`
+	Age        uint              `json:"age"`
+	Phone      string            `json:"phone,omitempty"`
+	Address    *Address          `json:"address,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Dob        string            `json:"dob,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	Invite     *Invite           `json:"invite,omitempty"`
+	Notes      []Note            `json:"notes,omitempty"`
+}
+type recordResult struct {
+	Id     string `json:"id"`
+	Result string `json:"result"`
 }
 
 func parseArgs() Arguments {
-	flagOperation := flag.String(operation, "", "Allowed values: [add|findById|remove|list]")
+	flagOperation := flag.String(operation, "", "Allowed values: [add|update|upsert|patch|changeId|copy|findById|exists|findByEmail|findByPhone|findByAge|sample|synthesize|remove|removeWhere|clear|sanitize|dedupe|validate|compact|sort|tenants|addNote|diff|list|head|tail|count|stats|search|fuzzyFind|distinct|birthdays|invite|acceptInvite|verifyEmails|schema|setMeta|unsetMeta|scanIds|reconcile|claim|release|export|report|importSql|migrateSchema|pseudonymize|depseudonymize]")
 	flagFileName := flag.String(userFileName, "", "Path to the JSON file with user's data.")
 	flagItem := flag.String(item, "", "User JSON, for example {''id'': ''1'', ''email'': ''email@test.com'', ''age'': 23}")
-	flagId := flag.String(id, "", "User Identifier, should be greater then zero")
+	var flagId multiValueFlag
+	flag.Var(&flagId, id, "User Identifier, should be greater then zero. remove accepts several comma separated or repeated ids")
+	var flagSet multiValueFlag
+	flag.Var(&flagSet, set, "Field assignment key=value applied to the item, can be repeated. Nested fields use dots: address.city=Kyiv")
+	flagPhone := flag.String(phone, "", "Phone number to look up with findByPhone.")
+	flagDefaultCountry := flag.String(defaultCountry, "", "ISO country code used for phone numbers without an international prefix, for example UA.")
+	flagEmail := flag.String(email, "", "Email to look up.")
+	flagDistance := flag.String(distance, "", "Maximum edit distance for fuzzyFind, 2 by default.")
+	flagFuzzy := flag.String(fuzzy, "", "Maximum edit distance at which dedupe also treats emails as the same.")
+	flagField := flag.String(field, "", "User field to report on, for example domain, age, country or meta.team.")
+	flagWithinDays := flag.String(withinDays, "", "Number of days ahead the birthdays report looks, 30 by default.")
+	flagAgeThreshold := flag.String(ageThreshold, "", "Only report birthdays where users turn this age.")
+	flagToken := flag.String(token, "", "One-time invite token for acceptInvite.")
+	flagInviteTtl := flag.String(inviteTtl, "", "How long an invite stays valid, 72h by default.")
+	flagSmtpAddr := flag.String(smtpAddr, "", "SMTP server host:port used to send notifications.")
+	flagSmtpFrom := flag.String(smtpFrom, "", "Sender address of notifications.")
+	flagSmtpUser := flag.String(smtpUser, "", "SMTP user name.")
+	flagSmtpPassword := flag.String(smtpPassword, os.Getenv(smtpPasswordEnv), "SMTP password. Defaults to $"+smtpPasswordEnv+".")
+	flagNotifyAdmins := flag.String(notifyAdmins, "", "Comma separated admin addresses notified of every invite, without its token.")
+	flagNotifyTemplate := flag.String(notifyTemplate, "", "Path to a text/template file overriding the invite message sent to the invitee.")
+	flagNoNotify := flag.Bool(noNotify, false, "Do not send notifications.")
+	flagWhere := flag.String(where, "", "Condition selecting the users removeWhere deletes, for example \"age < 18\".")
+	flagKeep := flag.String(keep, "", "Which duplicate dedupe keeps, or which record other operations keep for a duplicate id. Allowed values: [first|last|merge], merge is not allowed for dedupe")
+	flagBy := flag.String(byField, dedupeById, "Field sort orders by, or comma separated fields dedupe compares: [id|email].")
+	flagOrder := flag.String(order, ascending, "Sort order. Allowed values: [asc|desc]")
+	flagNote := flag.String(note, "", "Text of the note addNote appends to a user.")
+	flagHideNotes := flag.Bool(hideNotes, false, "Leave user notes out of the output of read only operations.")
+	flagPreserveFormat := flag.Bool(preserveFormat, false, "Keep the whitespace, key order and unknown keys of the users file, rewriting only changed records.")
+	flagLease := flag.String(lease, "", "How long claim makes this host the only writer of the users file, 24h by default.")
+	flagReportName := flag.String(reportName, "", "Name of the [reports.<name>] config section run by report.")
+	flagPretty := flag.Bool(pretty, false, "Indents the JSON written by list and findById.")
+	flagShowDiff := flag.Bool(showDiff, false, "Writes the changes an operation saved, as text or with -format json as JSON.")
+	flagUnmask := flag.Bool(unmask, false, "Shows the fields masked by the [visibility] of -profile when its [permissions] allow unmask.")
+	flagMove := flag.Bool(move, false, "Makes copy remove the copied users from the source file.")
+	flagConfirm := flag.Bool(confirm, false, "Confirms a destructive operation such as clear.")
+	flagStrictTypes := flag.Bool(strictTypes, false, "Reject ages given as floats or strings instead of converting them.")
+	flagConcurrency := flag.String(concurrency, "", "Number of parallel workers for verifyEmails and -files, 8 by default.")
+	flagRate := flag.String(rate, "", "Maximum DNS lookups per second for verifyEmails, 20 by default.")
+	flagQ := flag.String(q, "", "Words to search for across all user fields.")
+	flagQuery := flag.String(query, "", "Text search finds anywhere in the user id or email, ignoring case.")
+	flagFilter := flag.String(filter, "", "Expression users are filtered by, for example \"meta.team == 'sre' and age >= 18\".")
+	flagKey := flag.String(key, "", "Metadata key for setMeta and unsetMeta.")
+	flagValue := flag.String(value, "", "Metadata value for setMeta.")
+	flagCountry := flag.String(country, "", "ISO country code list results are filtered by.")
+	flagIdType := flag.String(idType, "", "Format of ids generated for added users without an id. Allowed values: [uuid|ulid|nanoid|int]")
+	flagIdPattern := flag.String(idPattern, "", "Regular expression every id has to match, for example EMP-\\d{6}.")
+	flagOnConflict := flag.String(onConflict, "", "Behavior of add when the id already exists. Allowed values: [skip|overwrite|fail|merge]")
+	flagOutput := flag.String(output, "", "Export target file. With -chunkSize it is a pattern such as users-%04d.json.")
+	flagFormat := flag.String(format, "", "Output format. Allowed values for list, head, tail and findById: [json|ndjson|csv|table|yaml|xml|xml:<root>|template|template:<name>], for export: the same and [mailmerge|sql], for schema: [jsonschema|go|typescript]")
+	flagTemplate := flag.String(templateFile, "", "Go template rendered per user by the mailmerge format, as a file path, or by the template format, inline or as a file path.")
+	flagChunkSize := flag.String(chunkSize, "", "Maximum number of users per exported file.")
+	flagIdsFile := flag.String(idsFile, "", "Path to a file with ids to remove, one per line or a JSON array.")
+	flagErrorFormat := flag.String(errorFormat, textErrorFormat, "Failure output format. Allowed values: [text|json]")
+	flagRetries := flag.String(retries, "", "Number of attempts for storage operations failing with a transient error.")
+	flagRetryBackoff := flag.String(retryBackoff, "", "Delay before the first retry, doubled on every next one, for example 200ms.")
+	flagRetryJitter := flag.String(retryJitter, "", "Maximum random delay added to every retry, for example 50ms.")
+	flagEmailKey := flag.String(emailKey, os.Getenv(emailKeyEnv), "Base64 encoded 32 byte key encrypting stored emails. Defaults to $"+emailKeyEnv+".")
+	flagPseudonymKey := flag.String(pseudonymKey, os.Getenv(pseudonymKeyEnv), "Secret used to derive email pseudonyms. Defaults to $"+pseudonymKeyEnv+".")
+	flagMappingFile := flag.String(mappingFile, "", "Path to the file mapping pseudonyms back to emails.")
+	flagNow := flag.String(nowFlag, "", "Overrides the current time, in RFC 3339 format, for reproducible runs.")
+	flagMirror := flag.String(mirror, "", "Path to a second users file every successful change is copied to.")
+	flagFallback := flag.String(fallback, "", "Path to a read-only users file used when the main one is unreachable or corrupted.")
+	flagMirrorMode := flag.String(mirrorMode, bestEffortMirror, "Whether a failed mirror write fails the operation. Allowed values: [best-effort|strict]")
+	flagMinAge := flag.String(minAge, "", "Lowest age, inclusive, returned by findByAge.")
+	flagMaxAge := flag.String(maxAge, "", "Highest age, inclusive, returned by findByAge.")
+	flagNumber := flag.String(number, "", "Number of users returned by sample, head and tail, 10 by default for head and tail.")
+	flagNewId := flag.String(newId, "", "Id a user gets from changeId.")
+	flagLike := flag.String(like, "", "Users file whose distributions synthesize follows.")
+	flagUserCount := flag.String(userCount, "", "Number of users generated by synthesize, 1000 by default.")
+	flagSeed := flag.String(seed, "", "Seed making sample and synthesize produce the same users again.")
+	flagTable := flag.String(table, defaultTable, "Table name used by the sql export format.")
+	flagDialect := flag.String(dialect, sqliteDialect, "SQL dialect of the sql export format. Allowed values: [sqlite|postgres|mysql]")
+	flagInput := flag.String(input, "", "Path to a SQL dump with INSERT statements read by importSql.")
+	flagTo := flag.String(to, "", "Schema version migrateSchema upgrades the users file to, the latest by default, or the users file copy writes to.")
+	flagConfig := flag.String(configFile, "", "Path to the config file, for example with a [templates] catalog.")
+	flagRules := flag.String(rules, "", "Comma-separated rule packs checked by validate, embedded [adult-only|has-phone|has-address] or [rules.<pack>] config sections.")
+	flagProfile := flag.String(profile, os.Getenv(profileEnv), "Profile whose [permissions] entry in the config file limits the allowed operations. Defaults to $"+profileEnv+".")
+	flagFiles := flag.String(files, "", "Glob of users files the operation runs against concurrently, for example 'data/*.json'.")
+	flagTenant := flag.String(tenant, "", "Tenant whose users file is used instead of -fileName.")
+	flagTenantPattern := flag.String(tenantPattern, "", "Users file of a tenant, %s is replaced by the tenant name. Defaults to pattern in the [tenants] config section or "+defaultTenantPattern+".")
+	flagFrom := flag.String(from, "", "Users file diff compares -fileName with.")
+	flagTimeout := flag.String(timeout, "", "Maximum duration of the storage operations, for example 5s.")
 	flag.Parse()
 
 	return Arguments{
-		operation:    *flagOperation,
-		item:         *flagItem,
-		id:           *flagId,
-		userFileName: *flagFileName}
+		operation:      *flagOperation,
+		item:           *flagItem,
+		set:            flagSet.String(),
+		id:             flagId.String(),
+		idsFile:        *flagIdsFile,
+		idType:         *flagIdType,
+		country:        *flagCountry,
+		filter:         *flagFilter,
+		q:              *flagQ,
+		query:          *flagQuery,
+		concurrency:    *flagConcurrency,
+		rate:           *flagRate,
+		smtpAddr:       *flagSmtpAddr,
+		smtpFrom:       *flagSmtpFrom,
+		smtpUser:       *flagSmtpUser,
+		smtpPassword:   *flagSmtpPassword,
+		notifyAdmins:   *flagNotifyAdmins,
+		notifyTemplate: *flagNotifyTemplate,
+		noNotify:       strconv.FormatBool(*flagNoNotify),
+		confirm:        strconv.FormatBool(*flagConfirm),
+		move:           strconv.FormatBool(*flagMove),
+		unmask:         strconv.FormatBool(*flagUnmask),
+		showDiff:       strconv.FormatBool(*flagShowDiff),
+		pretty:         strconv.FormatBool(*flagPretty),
+		reportName:     *flagReportName,
+		lease:          *flagLease,
+		strictTypes:    strconv.FormatBool(*flagStrictTypes),
+		note:           *flagNote,
+		hideNotes:      strconv.FormatBool(*flagHideNotes),
+		preserveFormat: strconv.FormatBool(*flagPreserveFormat),
+		where:          *flagWhere,
+		keep:           *flagKeep,
+		byField:        *flagBy,
+		order:          *flagOrder,
+		token:          *flagToken,
+		inviteTtl:      *flagInviteTtl,
+		withinDays:     *flagWithinDays,
+		ageThreshold:   *flagAgeThreshold,
+		field:          *flagField,
+		email:          *flagEmail,
+		distance:       *flagDistance,
+		fuzzy:          *flagFuzzy,
+		key:            *flagKey,
+		value:          *flagValue,
+		phone:          *flagPhone,
+		defaultCountry: *flagDefaultCountry,
+		idPattern:      *flagIdPattern,
+		onConflict:     *flagOnConflict,
+		output:         *flagOutput,
+		chunkSize:      *flagChunkSize,
+		format:         *flagFormat,
+		templateFile:   *flagTemplate,
+		userFileName:   *flagFileName,
+		errorFormat:    *flagErrorFormat,
+		retries:        *flagRetries,
+		retryBackoff:   *flagRetryBackoff,
+		retryJitter:    *flagRetryJitter,
+		timeout:        *flagTimeout,
+		mirror:         *flagMirror,
+		mirrorMode:     *flagMirrorMode,
+		fallback:       *flagFallback,
+		minAge:         *flagMinAge,
+		maxAge:         *flagMaxAge,
+		number:         *flagNumber,
+		seed:           *flagSeed,
+		like:           *flagLike,
+		newId:          *flagNewId,
+		userCount:      *flagUserCount,
+		table:          *flagTable,
+		dialect:        *flagDialect,
+		input:          *flagInput,
+		to:             *flagTo,
+		configFile:     *flagConfig,
+		rules:          *flagRules,
+		profile:        *flagProfile,
+		files:          *flagFiles,
+		tenant:         *flagTenant,
+		tenantPattern:  *flagTenantPattern,
+		from:           *flagFrom,
+		nowFlag:        *flagNow,
+		emailKey:       *flagEmailKey,
+		pseudonymKey:   *flagPseudonymKey,
+		mappingFile:    *flagMappingFile}
 }
 
+// Perform runs the operation of args, writing its data and messages such as
+// "Item with id 1 not found" to writer and storage warnings to stderr.
 func Perform(args Arguments, writer io.Writer) error {
+	return perform(args, writer, writer, os.Stderr)
+}
+
+// PerformTo runs the operation of args, writing data to writer and messages
+// and storage warnings to diagnostics. main passes stdout and stderr, and
+// tests pass buffers to check each stream alone.
+func PerformTo(args Arguments, writer, diagnostics io.Writer) error {
+	return perform(args, writer, diagnostics, diagnostics)
+}
+
+func perform(args Arguments, writer, diagnostics, warnings io.Writer) error {
 	operationArg := args[operation]
 	if len(operationArg) == 0 {
-		return errors.New("-operation flag has to be specified")
+		return missingFlagError(operation)
+	}
+	if err := checkPermission(args); err != nil {
+		return err
+	}
+	if operationArg == schemaOp {
+		return writeSchema(args[format], writer)
+	}
+	if operationArg == tenantsOp {
+		return listTenants(args, writer)
+	}
+	args, err := resolveTenant(args)
+	if err != nil {
+		return err
+	}
+	args, err = resolveTemplateFormat(args)
+	if err != nil {
+		return err
+	}
+	if len(args[files]) > 0 {
+		return forEachFile(args, writer, diagnostics, warnings)
 	}
 	fileNameArg := args[userFileName]
 	if len(fileNameArg) == 0 {
-		return errors.New("-fileName flag has to be specified")
+		return missingFlagError(userFileName)
 	}
 	idArg := args[id]
-	if (operationArg == removeOp || operationArg == findByIdOp) && len(idArg) == 0 {
-		return errors.New("-id flag has to be specified")
+	idsFileArg := args[idsFile]
+	if ((operationArg == removeOp && len(idsFileArg) == 0) || operationArg == findByIdOp || operationArg == patchOp || operationArg == changeIdOp || operationArg == copyOp || operationArg == existsOp) && len(idArg) == 0 {
+		return missingFlagError(id)
 	}
 	itemArg := args[item]
-	if (operationArg == addOp) && len(itemArg) == 0 {
-		return errors.New("-item flag has to be specified")
+	if (operationArg == addOp || operationArg == updateOp || operationArg == upsertOp || operationArg == patchOp) && len(itemArg) == 0 && len(args[set]) == 0 {
+		return missingFlagError(item)
+	}
+	if operationArg == findByPhoneOp && len(args[phone]) == 0 {
+		return missingFlagError(phone)
+	}
+	if operationArg == inviteOp && len(args[email]) == 0 {
+		return missingFlagError(email)
+	}
+	if operationArg == acceptInviteOp && len(args[token]) == 0 {
+		return missingFlagError(token)
+	}
+	if (operationArg == fuzzyFindOp || operationArg == findByEmailOp) && len(args[email]) == 0 {
+		return missingFlagError(email)
+	}
+	if (operationArg == setMetaOp || operationArg == unsetMetaOp) && len(idArg) == 0 {
+		return missingFlagError(id)
+	}
+	if (operationArg == setMetaOp || operationArg == unsetMetaOp) && len(args[key]) == 0 {
+		return missingFlagError(key)
+	}
+	strictTypesArg := args[strictTypes] == "true"
+	if operationArg == addOp || operationArg == updateOp || operationArg == upsertOp || operationArg == patchOp {
+		var err error
+		itemArg, err = applySetArgs(itemArg, args[set])
+		if err != nil {
+			return err
+		}
+		if !strictTypesArg {
+			itemArg, err = coerceItemAges(itemArg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	var match predicate
+	if filterArg := args[filter]; len(filterArg) > 0 {
+		var err error
+		match, err = parseFilter(filterArg)
+		if err != nil {
+			return err
+		}
+	}
+	idTypeArg := args[idType]
+	if err := validateIdTypeArg(idTypeArg); err != nil {
+		return err
+	}
+	pattern, err := compileIdPattern(args[idPattern])
+	if err != nil {
+		return err
+	}
+	countryArg := args[defaultCountry]
+	if err := validateDefaultCountry(countryArg); err != nil {
+		return err
+	}
+	onConflictArg := args[onConflict]
+	switch onConflictArg {
+	case "", skipOnConflict, overwriteOnConflict, failOnConflict, mergeOnConflict:
+	default:
+		return invalidFlagError(onConflict, onConflictArg)
+	}
+	ctx, cancel, err := operationContext(args)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	storage, err := newFileStorage(ctx, args)
+	if err != nil {
+		return err
+	}
+	storage.diagnostics, storage.warnings = diagnostics, warnings
+	if storage.showDiff {
+		defer writeSavedDiff(args[format], storage, writer)
 	}
 	switch operationArg {
 	case addOp:
-		return addUser(itemArg, fileNameArg, writer)
+		return addUser(itemArg, onConflictArg, idTypeArg, countryArg, pattern, storage.writable(), writer)
+	case upsertOp:
+		return addUser(itemArg, overwriteOnConflict, idTypeArg, countryArg, pattern, storage.writable(), writer)
+	case updateOp:
+		return updateUser(itemArg, idArg, countryArg, storage.writable(), writer)
+	case patchOp:
+		return patchUser(itemArg, idArg, countryArg, storage.writable(), writer)
 	case findByIdOp:
-		return findUserById(idArg, fileNameArg, writer)
+		return findUserById(idArg, args[format], args[templateSource], args[pretty] == "true", storage, writer)
+	case copyOp:
+		if len(args[to]) == 0 {
+			return missingFlagError(to)
+		}
+		targetArgs := make(Arguments, len(args))
+		for key, value := range args {
+			targetArgs[key] = value
+		}
+		delete(targetArgs, mirror)
+		delete(targetArgs, fallback)
+		targetArgs[userFileName] = args[to]
+		target, err := newFileStorage(ctx, targetArgs)
+		if err != nil {
+			return err
+		}
+		target.diagnostics, target.warnings = diagnostics, warnings
+		return copyUsers(splitIds(idArg), onConflictArg, args[move] == "true", storage.writable(), target.writable(), writer)
+	case existsOp:
+		return userExists(idArg, storage, writer)
+	case changeIdOp:
+		return changeUserId(idArg, args[newId], idTypeArg, pattern, storage.writable(), writer)
+	case importSqlOp:
+		return importSqlDump(args[input], onConflictArg, idTypeArg, countryArg, pattern, strictTypesArg, storage.writable(), writer)
+	case migrateSchemaOp:
+		return migrateSchema(args[to], storage.writable(), writer)
+	case findByAgeOp:
+		return findUsersByAge(args, storage, writer)
+	case sampleOp:
+		return sampleUsers(args[number], args[seed], storage, writer)
+	case synthesizeOp:
+		return synthesizeUsers(args, storage.writable(), writer)
+	case findByEmailOp:
+		return findUserByEmail(args[email], storage, writer)
 	case removeOp:
-		return removeUser(idArg, fileNameArg, writer)
+		if len(idsFileArg) > 0 {
+			return removeUsersFromFile(idsFileArg, storage.writable(), writer)
+		}
+		if strings.ContainsAny(idArg, ",\n") {
+			return removeUsers(splitIds(idArg), storage.writable(), writer)
+		}
+		return removeUser(idArg, storage.writable(), writer)
 	case listOp:
-		return listUsers(fileNameArg, writer)
+		fields, err := loadComputedFields(args)
+		if err != nil {
+			return err
+		}
+		return listUsers(strings.ToUpper(args[country]), args[format], args[templateSource], args[pretty] == "true", match, fields, storage, writer)
+	case headOp, tailOp:
+		fields, err := loadComputedFields(args)
+		if err != nil {
+			return err
+		}
+		return headOrTailUsers(operationArg, args, match, fields, storage, writer)
+	case countOp:
+		return countUsers(match, storage, writer)
+	case statsOp:
+		return usersFileStats(storage, writer)
+	case removeWhereOp:
+		if len(args[where]) == 0 {
+			return missingFlagError(where)
+		}
+		condition, err := parseFilter(args[where])
+		if err != nil {
+			return err
+		}
+		return removeUsersWhere(condition, storage.writable(), writer)
+	case sortOp:
+		return sortUsers(args[byField], args[order], storage.writable(), writer)
+	case compactOp:
+		return compactUsers(storage.writable(), writer)
+	case validateOp:
+		enabledRules, err := loadRules(args)
+		if err != nil {
+			return err
+		}
+		return validateUsersFile(enabledRules, storage, writer)
+	case diffOp:
+		return diffUsers(args[from], storage, writer)
+	case addNoteOp:
+		if len(idArg) == 0 {
+			return missingFlagError(id)
+		}
+		return addUserNote(idArg, args[note], storage.writable(), writer)
+	case dedupeOp:
+		return dedupeUsers(args[keep], args[byField], args[fuzzy], storage.writable(), writer)
+	case sanitizeOp:
+		return sanitizeUsers(storage.writable(), writer)
+	case clearOp:
+		return clearUsers(args[confirm] == "true", storage.writable(), writer)
+	case setMetaOp:
+		return setUserMeta(idArg, args[key], args[value], storage.writable(), writer)
+	case unsetMetaOp:
+		return unsetUserMeta(idArg, args[key], storage.writable(), writer)
+	case findByPhoneOp:
+		return findUserByPhone(args[phone], countryArg, storage, writer)
+	case fuzzyFindOp:
+		return fuzzyFindUsers(args[email], args[distance], storage, writer)
+	case distinctOp:
+		return distinctValues(args[field], storage, writer)
+	case birthdaysOp:
+		return upcomingBirthdays(args[withinDays], args[ageThreshold], storage, writer)
+	case inviteOp:
+		return inviteUser(args, pattern, storage.writable(), writer)
+	case acceptInviteOp:
+		return acceptInvite(args[token], itemArg, countryArg, storage.writable(), writer)
+	case verifyEmailsOp:
+		return verifyEmails(args, storage, writer)
+	case searchOp:
+		return searchUsers(args[q], args[query], storage, writer)
+	case scanIdsOp:
+		return scanIds(pattern, storage, writer)
+	case reconcileOp:
+		return reconcileMirror(storage.writable(), writer)
+	case exportOp:
+		return exportUsers(args, storage, writer)
+	case claimOp:
+		return claimOwnership(args[lease], storage, writer)
+	case releaseOp:
+		return releaseOwnership(storage, writer)
+	case reportOp:
+		return runReport(args, storage, writer)
+	case pseudonymizeOp:
+		return pseudonymizeUsers(args, storage.writable(), writer)
+	case depseudonymizeOp:
+		return depseudonymizeUsers(args, storage.writable(), writer)
 	default:
-		return fmt.Errorf("Operation %s not allowed!", operationArg)
+		return newOperationError(CodeValidation, fmt.Errorf("Operation %s not allowed!", operationArg),
+			map[string]string{operation: operationArg})
 	}
-	return nil
+}
+
+func missingFlagError(flagName string) error {
+	return newOperationError(CodeValidation, fmt.Errorf("-%s flag has to be specified", flagName),
+		map[string]string{"flag": flagName})
 }
 
 func main() {
-	err := Perform(parseArgs(), os.Stdout)
+	args := parseArgs()
+	err := configureClock(args[nowFlag])
+	if err == nil {
+		err = PerformTo(args, os.Stdout, os.Stderr)
+	}
 	if err != nil {
-		panic(err)
+		if args[errorFormat] == jsonErrorFormat {
+			writeJSONError(err, os.Stderr)
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(ErrorCodeOf(err).ExitCode())
 	}
 }
 
-func removeUser(userId, fileName string, writer io.Writer) error {
-	users, err := loadUsersFromFile(fileName)
+func removeUser(userId string, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
 	if err != nil {
 		return err
 	}
@@ -97,30 +541,186 @@ func removeUser(userId, fileName string, writer io.Writer) error {
 		}
 	}
 	if !found {
-		return fmt.Errorf(userNotFoundMsg, userId)
+		storage.diagnostics.Write([]byte(fmt.Sprintf(userNotFoundMsg, userId)))
+		return nil
 	}
-	err = saveUsersToFile(users, fileName)
+	err = storage.save(users)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func listUsers(fileName string, writer io.Writer) error {
-	users, err := loadUsersFromFile(fileName)
+func removeUsersFromFile(idsFileName string, storage *fileStorage, writer io.Writer) error {
+	ids, err := readIdsFile(idsFileName)
+	if err != nil {
+		return err
+	}
+	return removeUsers(ids, storage, writer)
+}
+
+func splitIds(idArg string) []string {
+	var ids []string
+	for _, userId := range strings.FieldsFunc(idArg, func(r rune) bool { return r == ',' || r == '\n' }) {
+		userId = strings.TrimSpace(userId)
+		if len(userId) > 0 {
+			ids = append(ids, userId)
+		}
+	}
+	return ids
+}
+
+func removeUsers(ids []string, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	pending := make(map[string]bool, len(ids))
+	for _, userId := range ids {
+		pending[userId] = true
+	}
+	removed := make(map[string]bool, len(ids))
+	remaining := users[:0]
+	for _, cUser := range users {
+		if pending[cUser.Id] {
+			removed[cUser.Id] = true
+			continue
+		}
+		remaining = append(remaining, cUser)
+	}
+	results := make([]recordResult, 0, len(ids))
+	for _, userId := range ids {
+		result := notFoundResult
+		if removed[userId] {
+			result = removedResult
+		}
+		results = append(results, recordResult{Id: userId, Result: result})
+	}
+	if len(removed) > 0 {
+		err = storage.save(remaining)
+		if err != nil {
+			return err
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
+}
+
+func readIdsFile(idsFileName string) ([]string, error) {
+	data, err := os.ReadFile(osPath(idsFileName))
+	if err != nil {
+		return nil, newOperationError(CodeStorageIO, fmt.Errorf("Error while reading ids file: %w", err),
+			map[string]string{idsFile: idsFileName})
+	}
+	content := strings.TrimSpace(string(data))
+	var ids []string
+	if strings.HasPrefix(content, "[") {
+		err = json.Unmarshal([]byte(content), &ids)
+		if err != nil {
+			return nil, newOperationError(CodeValidation, fmt.Errorf("Error to unmarshal ids file: %w", err),
+				map[string]string{idsFile: idsFileName})
+		}
+		return ids, nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 {
+			ids = append(ids, line)
+		}
+	}
+	return ids, nil
+}
+
+func listUsers(countryArg, formatArg, sourceArg string, prettyArg bool, match predicate, fields []computedField, storage *fileStorage, writer io.Writer) error {
+	if err := validateUsersFormat(formatArg); err != nil {
+		return err
+	}
+	users, err := storage.load()
 	if err != nil {
 		return err
 	}
-	usersData, err := json.Marshal(users)
+	if len(countryArg) > 0 {
+		users = filterByCountry(users, countryArg)
+	}
+	if match != nil {
+		users = filterUsers(users, match)
+	}
+	if prettyArg && (formatArg == "" || formatArg == jsonFormat) {
+		usersData, err := marshalUsers(users, fields)
+		if err != nil {
+			return err
+		}
+		return writeJSON(usersData, prettyArg, writer)
+	}
+	return writeUsersAs(formatArg, sourceArg, users, fields, writer)
+}
+
+func countUsers(match predicate, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
 	if err != nil {
-		return fmt.Errorf(marshalingErrorMsg, err)
+		return err
 	}
-	writer.Write(usersData)
+	if match != nil {
+		users = filterUsers(users, match)
+	}
+	writer.Write([]byte(strconv.Itoa(len(users))))
 	return nil
 }
 
-func findUserById(idArg, fileName string, writer io.Writer) error {
-	users, err := loadUsersFromFile(fileName)
+func removeUsersWhere(condition predicate, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	results := []recordResult{}
+	remaining := users[:0]
+	for _, cUser := range users {
+		if condition(cUser) {
+			results = append(results, recordResult{Id: cUser.Id, Result: removedResult})
+			continue
+		}
+		remaining = append(remaining, cUser)
+	}
+	if len(results) > 0 {
+		err = storage.save(remaining)
+		if err != nil {
+			return err
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
+}
+
+func clearUsers(confirmed bool, storage *fileStorage, writer io.Writer) error {
+	if !confirmed {
+		return newOperationError(CodeValidation, fmt.Errorf("Operation %s removes all users, pass -%s to proceed", clearOp, confirm),
+			map[string]string{"flag": confirm})
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	err = storage.save([]User{})
+	if err != nil {
+		return err
+	}
+	writer.Write([]byte(fmt.Sprintf("%d users removed", len(users))))
+	return nil
+}
+
+func findUserById(idArg, formatArg, sourceArg string, prettyArg bool, storage *fileStorage, writer io.Writer) error {
+	if err := validateUsersFormat(formatArg); err != nil {
+		return err
+	}
+	users, err := storage.load()
 	if err != nil {
 		return err
 	}
@@ -132,75 +732,316 @@ func findUserById(idArg, fileName string, writer io.Writer) error {
 	}
 	if user.Id == "" {
 		writer.Write([]byte(""))
-		return fmt.Errorf(userNotFoundMsg, idArg)
+		return nil
+	}
+	// Formats other than json and yaml write the user as a list of one.
+	switch formatArg {
+	case "", jsonFormat, yamlFormat:
+	default:
+		return writeUsersAs(formatArg, sourceArg, []User{user}, nil, writer)
 	}
 	userData, err := json.Marshal(user)
 	if err != nil {
-		return fmt.Errorf(marshalingErrorMsg, err)
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
 	}
-	writer.Write(userData)
+	if formatArg == yamlFormat {
+		return writeYAML(userData, writer)
+	}
+	return writeJSON(userData, prettyArg, writer)
+}
+
+func findUserByEmail(emailArg string, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	wanted := foldText(asciiEmail(normalizeText(sanitizeText(emailArg, false))))
+	for _, user := range users {
+		if foldText(asciiEmail(user.Email)) == wanted || (len(user.EmailAscii) > 0 && foldText(user.EmailAscii) == wanted) {
+			userData, err := json.Marshal(user)
+			if err != nil {
+				return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+			}
+			writer.Write(userData)
+			return nil
+		}
+	}
+	writer.Write([]byte(""))
 	return nil
 }
 
-func addUser(item, fileName string, writer io.Writer) error {
+func addUser(item, onConflictArg, idTypeArg, countryArg string, pattern *regexp.Regexp, storage *fileStorage, writer io.Writer) error {
+	if strings.HasPrefix(strings.TrimSpace(item), "[") {
+		return addUsers(item, onConflictArg, idTypeArg, countryArg, pattern, storage, writer)
+	}
 	var pendingUser User
 	err := json.Unmarshal([]byte(item), &pendingUser)
 	if err != nil {
-		return fmt.Errorf(unmarshalingErrorMsg, err)
+		return newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
 	}
-	users, err := loadUsersFromFile(fileName)
+	users, err := storage.load()
 	if err != nil {
 		return err
 	}
-	for _, user := range users {
-		if user.Id == pendingUser.Id {
-			writer.Write([]byte("Item with id " + user.Id + " already exists"))
-			return nil
+	err = prepareUser(&pendingUser, users, idTypeArg, countryArg, pattern)
+	if err != nil {
+		return err
+	}
+	if len(onConflictArg) == 0 {
+		for _, user := range users {
+			if user.Id == pendingUser.Id {
+				storage.diagnostics.Write([]byte("Item with id " + user.Id + " already exists"))
+				return nil
+			}
+		}
+		users = append(users, pendingUser)
+		err = storage.save(users)
+		if err != nil {
+			return fmt.Errorf("failed to save users: %w", err)
+		}
+		return nil
+	}
+
+	users, result, err := applyConflictPolicy(users, pendingUser, onConflictArg)
+	if err != nil {
+		return err
+	}
+	if result != skippedResult {
+		err = storage.save(users)
+		if err != nil {
+			return fmt.Errorf("failed to save users: %w", err)
 		}
 	}
-	users = append(users, pendingUser)
-	err = saveUsersToFile(users, fileName)
+	resultsData, err := json.Marshal([]recordResult{{Id: pendingUser.Id, Result: result}})
 	if err != nil {
-		return fmt.Errorf("failed to save users: %w", err)
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
 	}
+	writer.Write(resultsData)
 	return nil
 }
 
-func loadUsersFromFile(fileName string) ([]User, error) {
-	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, 0755)
+func updateUser(item, idArg, countryArg string, storage *fileStorage, writer io.Writer) error {
+	var updatedUser User
+	err := json.Unmarshal([]byte(item), &updatedUser)
 	if err != nil {
-		return nil, fmt.Errorf(openFileErrorMsg, err)
+		return newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
 	}
-	defer file.Close()
+	if len(updatedUser.Id) == 0 {
+		updatedUser.Id = idArg
+	}
+	if len(updatedUser.Id) == 0 {
+		return missingFlagError(id)
+	}
+	err = normalizeUser(&updatedUser, countryArg)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	for i, user := range users {
+		if user.Id == updatedUser.Id {
+			users[i] = updatedUser
+			return storage.save(users)
+		}
+	}
+	storage.diagnostics.Write([]byte(fmt.Sprintf(userNotFoundMsg, updatedUser.Id)))
+	return nil
+}
 
-	usersData, err := io.ReadAll(file)
-	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("Error while reading users from file: %w", err)
+func normalizeUser(user *User, countryArg string) error {
+	sanitizeUser(user)
+	normalizeUserText(user)
+	var err error
+	user.EmailAscii, err = idnEmail(user.Email)
+	if err != nil {
+		return err
 	}
-	var users []User
-	if len(usersData) > 0 {
-		err = json.Unmarshal(usersData, &users)
+	user.Phone, err = normalizePhone(user.Phone, countryArg)
+	if err != nil {
+		return err
+	}
+	err = normalizeAddress(user.Address)
+	if err != nil {
+		return err
+	}
+	return validateDob(user.Dob)
+}
+
+// addUsers inserts a JSON array of users with a single save. Without
+// -onConflict existing ids are skipped, like the single item add does.
+func addUsers(item, onConflictArg, idTypeArg, countryArg string, pattern *regexp.Regexp, storage *fileStorage, writer io.Writer) error {
+	var pendingUsers []User
+	err := json.Unmarshal([]byte(item), &pendingUsers)
+	if err != nil {
+		return newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+	}
+	if len(onConflictArg) == 0 {
+		onConflictArg = skipOnConflict
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	changed := false
+	results := make([]recordResult, 0, len(pendingUsers))
+	for _, pendingUser := range pendingUsers {
+		err = prepareUser(&pendingUser, users, idTypeArg, countryArg, pattern)
+		if err != nil {
+			return err
+		}
+		var result string
+		users, result, err = applyConflictPolicy(users, pendingUser, onConflictArg)
 		if err != nil {
-			return nil, fmt.Errorf(unmarshalingErrorMsg, err)
+			return err
 		}
+		changed = changed || result != skippedResult
+		results = append(results, recordResult{Id: pendingUser.Id, Result: result})
 	}
-	return users, nil
+	if changed {
+		err = storage.save(users)
+		if err != nil {
+			return fmt.Errorf("failed to save users: %w", err)
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
 }
 
-func saveUsersToFile(users []User, fileName string) error {
-	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+func prepareUser(pendingUser *User, users []User, idTypeArg, countryArg string, pattern *regexp.Regexp) error {
+	var err error
+	if len(pendingUser.Id) == 0 && len(idTypeArg) > 0 {
+		pendingUser.Id, err = generateId(idTypeArg, users)
+	} else {
+		err = validateIdFormat(pendingUser.Id, idTypeArg)
+	}
+	if err != nil {
+		return err
+	}
+	err = validateIdPattern(pendingUser.Id, pattern)
 	if err != nil {
-		return fmt.Errorf(openFileErrorMsg, err)
+		return err
+	}
+	return normalizeUser(pendingUser, countryArg)
+}
+
+func applyConflictPolicy(users []User, pendingUser User, policy string) ([]User, string, error) {
+	for i, user := range users {
+		if user.Id != pendingUser.Id {
+			continue
+		}
+		switch policy {
+		case skipOnConflict:
+			return users, skippedResult, nil
+		case overwriteOnConflict:
+			users[i] = pendingUser
+			return users, overwrittenResult, nil
+		case mergeOnConflict:
+			users[i] = mergeUsers(user, pendingUser)
+			return users, mergedResult, nil
+		default:
+			return nil, "", newOperationError(CodeConflict, fmt.Errorf("Item with id %s already exists", user.Id),
+				map[string]string{id: user.Id})
+		}
+	}
+	return append(users, pendingUser), addedResult, nil
+}
+
+func mergeUsers(existing, incoming User) User {
+	if len(incoming.Email) > 0 {
+		existing.Email = incoming.Email
+		existing.EmailAscii = incoming.EmailAscii
+	}
+	if incoming.Age > 0 {
+		existing.Age = incoming.Age
+	}
+	if len(incoming.Phone) > 0 {
+		existing.Phone = incoming.Phone
+	}
+	if incoming.Address != nil {
+		existing.Address = incoming.Address
+	}
+	if len(incoming.Dob) > 0 {
+		existing.Dob = incoming.Dob
+	}
+	for metaKey, metaValue := range incoming.Metadata {
+		if existing.Metadata == nil {
+			existing.Metadata = map[string]string{}
+		}
+		existing.Metadata[metaKey] = metaValue
+	}
+	return existing
+}
+
+func loadUsersFromFile(fileName string) ([]User, fileLayout, error) {
+	file, err := os.OpenFile(osPath(fileName), os.O_RDWR|os.O_CREATE, 0755)
+	if err != nil {
+		return nil, fileLayout{}, newOperationError(CodeStorageIO, fmt.Errorf(openFileErrorMsg, err),
+			map[string]string{userFileName: fileName})
 	}
 	defer file.Close()
 
-	jsonData, err := json.Marshal(users)
+	usersData, err := io.ReadAll(file)
+	if err != nil && err != io.EOF {
+		return nil, fileLayout{}, newOperationError(CodeStorageIO, fmt.Errorf("Error while reading users from file: %w", err),
+			map[string]string{userFileName: fileName})
+	}
+	users, layout, err := decodeUsers(usersData)
+	if err != nil {
+		return nil, fileLayout{}, withDetail(err, userFileName, fileName)
+	}
+	layout.source = usersData
+	return users, layout, nil
+}
+
+func saveUsersToFile(users []User, layout fileLayout, fileName string, commit func() error) error {
+	jsonData, err := encodeUsers(users, layout)
+	if err != nil {
+		return err
+	}
+	return writeUsersData(jsonData, fileName, commit)
+}
+
+// writeUsersData writes a shadow copy next to the users file and renames it
+// over the file, so that a concurrent reader sees either the previous or the
+// next version and never a half written one. The rename only happens when
+// commit, if given, succeeds.
+func writeUsersData(jsonData []byte, fileName string, commit func() error) error {
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(osPath(fileName)); err == nil {
+		mode = info.Mode().Perm()
+	}
+	file, err := os.CreateTemp(filepath.Dir(osPath(fileName)), "."+filepath.Base(fileName)+".*")
 	if err != nil {
-		return fmt.Errorf(marshalingErrorMsg, err)
+		return newOperationError(CodeStorageIO, fmt.Errorf(openFileErrorMsg, err),
+			map[string]string{userFileName: fileName})
 	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
 	_, err = file.Write(jsonData)
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+	if err == nil {
+		err = file.Close()
+	}
+	if err == nil && commit != nil {
+		if err := commit(); err != nil {
+			return err
+		}
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), osPath(fileName))
+	}
 	if err != nil {
-		return fmt.Errorf("Error while writing users to a file: %w", err)
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing users to a file: %w", err),
+			map[string]string{userFileName: fileName})
 	}
 
 	return nil
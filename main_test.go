@@ -404,3 +404,489 @@ func TestRemovingOperation(t *testing.T) {
 		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
 	}
 }
+
+// Error reporting tests
+func TestJSONErrorOutput(t *testing.T) {
+	var buffer bytes.Buffer
+	args := Arguments{
+		"id":        "",
+		"operation": "abcd",
+		"item":      "",
+		"fileName":  fileName,
+	}
+	expectedOutput := "{\"code\":\"VALIDATION\",\"message\":\"Operation abcd not allowed!\",\"details\":{\"operation\":\"abcd\"}}\n"
+
+	err := Perform(args, &buffer)
+	if err == nil {
+		t.Fatal("Expect error when wrong -operation passed")
+	}
+
+	var errBuffer bytes.Buffer
+	err = writeJSONError(err, &errBuffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if errBuffer.String() != expectedOutput {
+		t.Errorf("Expect error output to be '%s', but got '%s'", expectedOutput, errBuffer.String())
+	}
+}
+
+func TestErrorCodeOfMissingFlag(t *testing.T) {
+	var buffer bytes.Buffer
+	args := Arguments{
+		"id":        "",
+		"operation": "remove",
+		"item":      "",
+		"fileName":  fileName,
+	}
+
+	err := Perform(args, &buffer)
+	if err == nil {
+		t.Fatal("Expect error when -id flag is missing")
+	}
+
+	if code := ErrorCodeOf(err); code != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, code)
+	}
+	if exitCode := ErrorCodeOf(err).ExitCode(); exitCode != 2 {
+		t.Errorf("Expect exit code to be 2, but got %d", exitCode)
+	}
+}
+
+func TestRemovingOperationIdsFile(t *testing.T) {
+	var buffer bytes.Buffer
+	idsFileName := "ids.txt"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(idsFileName, []byte("1\n4\n3\n"), filePermission)
+	defer os.Remove(idsFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"removed\"},{\"id\":\"4\",\"result\":\"not found\"},{\"id\":\"3\",\"result\":\"removed\"}]"
+	expectedFileContent := "[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"
+	args := Arguments{
+		"id":        "",
+		"idsFile":   idsFileName,
+		"operation": "remove",
+		"item":      "",
+		"fileName":  fileName,
+	}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestRemovingOperationIdList(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"removed\"},{\"id\":\"4\",\"result\":\"not found\"},{\"id\":\"3\",\"result\":\"removed\"}]"
+	expectedFileContent := "[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"
+	args := Arguments{
+		"id":        "1, 4\n3",
+		"operation": "remove",
+		"item":      "",
+		"fileName":  fileName,
+	}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestAddingOperationMergeOnConflict(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"merged\"}]"
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":35}]"
+	args := Arguments{
+		"id":         "",
+		"operation":  "add",
+		"item":       "{\"id\":\"1\",\"age\":35}",
+		"onConflict": "merge",
+		"fileName":   fileName,
+	}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestAddingOperationFailOnConflict(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{
+		"id":         "",
+		"operation":  "add",
+		"item":       "{\"id\":\"1\",\"email\":\"other@test.com\",\"age\":35}",
+		"onConflict": "fail",
+		"fileName":   fileName,
+	}
+
+	err = Perform(args, &buffer)
+
+	if code := ErrorCodeOf(err); code != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, code)
+	}
+}
+
+// Update operation tests
+func TestUpdateOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"new@test.com\",\"age\":35},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"
+	args := Arguments{
+		"id":        "",
+		"operation": "update",
+		"item":      "{\"id\":\"1\",\"email\":\"new@test.com\",\"age\":35}",
+		"fileName":  fileName,
+	}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestUpdateOperationWrongID(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "Item with id 2 not found"
+	args := Arguments{
+		"id":        "",
+		"operation": "update",
+		"item":      "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}",
+		"fileName":  fileName,
+	}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+// Upsert operation tests
+func TestUpsertOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{
+		"id":        "",
+		"operation": "upsert",
+		"item":      "{\"id\":\"1\",\"email\":\"new@test.com\",\"age\":35}",
+		"fileName":  fileName,
+	}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	args["item"] = "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}"
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"overwritten\"}][{\"id\":\"2\",\"result\":\"added\"}]"
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"new@test.com\",\"age\":35},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+// Count operation tests
+func TestCountOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{
+		"id":        "",
+		"operation": "count",
+		"item":      "",
+		"filter":    "age >= 18",
+		"fileName":  fileName,
+	}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != "1" {
+		t.Errorf("Expect output to be '1', but got '%s'", buffer.String())
+	}
+}
+
+func TestFindByEmailOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{
+		"operation": "findByEmail",
+		"email":     "Test2@Test.com",
+		"fileName":  fileName,
+	}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestClearOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "clear", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+
+	err = Perform(Arguments{"operation": "clear", "confirm": "true", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	file, err := os.OpenFile(fileName, os.O_RDONLY, filePermission)
+	defer file.Close()
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != "[]" {
+		t.Errorf("Expect file content to be '[]', but got '%s'", bytes)
+	}
+}
+
+func TestAddingBatchOfUsers(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{
+		"operation": "add",
+		"item":      "[{\"id\":\"1\",\"email\":\"new@test.com\",\"age\":35},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]",
+		"fileName":  fileName,
+	}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"skipped\"},{\"id\":\"2\",\"result\":\"added\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestRemoveWhereOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@old-domain.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{
+		"operation": "removeWhere",
+		"where":     "age<18 or email endsWith @old-domain.com",
+		"fileName":  fileName,
+	}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"removed\"},{\"id\":\"2\",\"result\":\"removed\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":22}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestOperationNotPermittedForProfile(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+	defer os.Remove(fileName)
+
+	err := ioutil.WriteFile(configFileName, []byte("[permissions]\ncron = \"list, export\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "list", "profile": "cron", "config": configFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args[operation] = "clear"
+	err = Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodePermissionDenied {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodePermissionDenied, ErrorCodeOf(err))
+	}
+	if ErrorCodeOf(err).ExitCode() != 8 {
+		t.Errorf("Expect exit code to be '8', but got '%d'", ErrorCodeOf(err).ExitCode())
+	}
+}
+
+func TestPerformToSeparatesDiagnostics(t *testing.T) {
+	var data, diagnostics bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = PerformTo(Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}", "fileName": fileName}, &data, &diagnostics)
+	if err != nil {
+		t.Error(err)
+	}
+	if data.String() != "" {
+		t.Errorf("Expect output to be '%s', but got '%s'", "", data.String())
+	}
+	expectedOutput := "Item with id 1 already exists"
+	if diagnostics.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, diagnostics.String())
+	}
+}
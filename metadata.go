@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	setMetaOp   = "setMeta"
+	unsetMetaOp = "unsetMeta"
+	key         = "key"
+	value       = "value"
+)
+
+func setUserMeta(userId, keyArg, valueArg string, storage *fileStorage, writer io.Writer) error {
+	return updateUserMeta(userId, storage, writer, func(user *User) {
+		if user.Metadata == nil {
+			user.Metadata = map[string]string{}
+		}
+		user.Metadata[keyArg] = valueArg
+	})
+}
+
+func unsetUserMeta(userId, keyArg string, storage *fileStorage, writer io.Writer) error {
+	return updateUserMeta(userId, storage, writer, func(user *User) {
+		delete(user.Metadata, keyArg)
+		if len(user.Metadata) == 0 {
+			user.Metadata = nil
+		}
+	})
+}
+
+func updateUserMeta(userId string, storage *fileStorage, writer io.Writer, change func(user *User)) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		if users[i].Id == userId {
+			change(&users[i])
+			return storage.save(users)
+		}
+	}
+	storage.diagnostics.Write([]byte(fmt.Sprintf(userNotFoundMsg, userId)))
+	return nil
+}
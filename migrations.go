@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	migrateSchemaOp      = "migrateSchema"
+	to                   = "to"
+	legacySchemaVersion  = 1
+	currentSchemaVersion = 2
+)
+
+// usersDocument is the layout of versioned users files. Files holding a bare
+// JSON array predate it and are schema version 1.
+type usersDocument struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Users         []json.RawMessage `json:"users"`
+}
+
+// fileLayout describes how a users file was written so that saving it keeps
+// the same shape.
+type fileLayout struct {
+	version  int
+	indented bool
+	source   []byte
+}
+
+// schemaMigrations upgrades the raw user records of a file from the version
+// used as the key to the next one.
+var schemaMigrations = map[int]func(records []map[string]interface{}) error{
+	// Version 2 only introduces the document header.
+	1: func(records []map[string]interface{}) error { return nil },
+}
+
+func decodeUsers(usersData []byte) ([]User, fileLayout, error) {
+	usersData = bytes.TrimSpace(usersData)
+	layout := fileLayout{version: legacySchemaVersion}
+	if len(usersData) == 0 {
+		return nil, layout, nil
+	}
+	layout.indented = len(usersData) > 1 && usersData[1] == '\n'
+	var rawUsers []json.RawMessage
+	if usersData[0] == '{' {
+		var document usersDocument
+		err := json.Unmarshal(usersData, &document)
+		if err != nil {
+			return nil, layout, newOperationError(CodeInvalidData, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+		if document.SchemaVersion < legacySchemaVersion || document.SchemaVersion > currentSchemaVersion {
+			return nil, layout, newOperationError(CodeInvalidData,
+				fmt.Errorf("Users file has schema version %d, this build supports versions up to %d", document.SchemaVersion, currentSchemaVersion),
+				map[string]string{"schemaVersion": strconv.Itoa(document.SchemaVersion)})
+		}
+		layout.version, rawUsers = document.SchemaVersion, document.Users
+	} else {
+		err := json.Unmarshal(usersData, &rawUsers)
+		if err != nil {
+			return nil, layout, newOperationError(CodeInvalidData, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+	}
+	users, err := migrateUsers(rawUsers, layout.version)
+	return users, layout, err
+}
+
+func migrateUsers(rawUsers []json.RawMessage, version int) ([]User, error) {
+	if version < currentSchemaVersion && rawUsers != nil {
+		records := make([]map[string]interface{}, len(rawUsers))
+		for i, rawUser := range rawUsers {
+			err := json.Unmarshal(rawUser, &records[i])
+			if err != nil {
+				return nil, newOperationError(CodeInvalidData, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+			}
+		}
+		for from := version; from < currentSchemaVersion; from++ {
+			err := schemaMigrations[from](records)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i, record := range records {
+			rawUser, err := json.Marshal(record)
+			if err != nil {
+				return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+			}
+			rawUsers[i] = rawUser
+		}
+	}
+	var users []User
+	for _, rawUser := range rawUsers {
+		var user User
+		err := json.Unmarshal(rawUser, &user)
+		if err != nil {
+			return nil, newOperationError(CodeInvalidData, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// encodeUsers keeps legacy files a bare array so that older builds can still
+// read them until migrateSchema upgrades the file explicitly.
+func encodeUsers(users []User, layout fileLayout) ([]byte, error) {
+	var document interface{} = users
+	if layout.version > legacySchemaVersion {
+		document = struct {
+			SchemaVersion int    `json:"schemaVersion"`
+			Users         []User `json:"users"`
+		}{layout.version, users}
+	}
+	var usersData []byte
+	var err error
+	if layout.indented {
+		usersData, err = json.MarshalIndent(document, "", "  ")
+		usersData = append(usersData, '\n')
+	} else {
+		usersData, err = json.Marshal(document)
+	}
+	if err != nil {
+		return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	return usersData, nil
+}
+
+func migrateSchema(toArg string, storage *fileStorage, writer io.Writer) error {
+	target := currentSchemaVersion
+	if len(toArg) > 0 {
+		var err error
+		target, err = strconv.Atoi(toArg)
+		if err != nil || target < legacySchemaVersion || target > currentSchemaVersion {
+			return invalidFlagError(to, toArg)
+		}
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	if target < storage.layout.version {
+		return newOperationError(CodeConflict,
+			fmt.Errorf("Users file has schema version %d, downgrading to %d is not supported", storage.layout.version, target),
+			map[string]string{to: toArg})
+	}
+	from := storage.layout.version
+	storage.layout.version = target
+	err = storage.save(users)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "Schema version %d migrated to %d", from, target)
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMigrateSchemaOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "migrateSchema", "to": "2", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "Schema version 1 migrated to 2"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "{\"schemaVersion\":2,\"users\":[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]}"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+
+	err = Perform(Arguments{"operation": "migrateSchema", "to": "1", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, ErrorCodeOf(err))
+	}
+}
+
+func TestNewerSchemaVersionIsRejected(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("{\"schemaVersion\":3,\"users\":[]}"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "list", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeInvalidData {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeInvalidData, ErrorCodeOf(err))
+	}
+}
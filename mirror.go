@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	mirror           = "mirror"
+	mirrorMode       = "mirrorMode"
+	reconcileOp      = "reconcile"
+	bestEffortMirror = "best-effort"
+	strictMirror     = "strict"
+)
+
+func validateMirrorArgs(args Arguments) error {
+	switch args[mirrorMode] {
+	case "", bestEffortMirror, strictMirror:
+	default:
+		return invalidFlagError(mirrorMode, args[mirrorMode])
+	}
+	if len(args[mirror]) > 0 && samePath(args[mirror], args[userFileName]) {
+		return invalidFlagError(mirror, args[mirror])
+	}
+	return nil
+}
+
+func (s *fileStorage) saveMirror(users []User) error {
+	if len(s.mirror) == 0 {
+		return nil
+	}
+	err := s.writeMirror(users)
+	if err == nil {
+		return nil
+	}
+	if s.strict {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Users were saved but the mirror %s was not updated: %w", s.mirror, err),
+			map[string]string{mirror: s.mirror})
+	}
+	fmt.Fprintf(s.warnings, "Warning: mirror %s was not updated: %s\n", s.mirror, err)
+	return nil
+}
+
+func (s *fileStorage) writeMirror(users []User) error {
+	return s.withRetry(func() error {
+		return s.run(func(commit func() error) error {
+			return saveUsersToFile(users, s.layout, s.mirror, commit)
+		})
+	})
+}
+
+func reconcileMirror(storage *fileStorage, writer io.Writer) error {
+	if len(storage.mirror) == 0 {
+		return missingFlagError(mirror)
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	if storage.emails != nil {
+		users, err = storage.emails.encryptUsers(users)
+		if err != nil {
+			return err
+		}
+	}
+	err = storage.writeMirror(users)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "%d users copied to %s", len(users), storage.mirror)
+	return nil
+}
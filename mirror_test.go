@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMirrorReceivesMutations(t *testing.T) {
+	var buffer bytes.Buffer
+	mirrorFileName := "mirror.json"
+	defer os.Remove(fileName)
+	defer os.Remove(mirrorFileName)
+
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}", "mirror": mirrorFileName, "fileName": fileName}
+
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(mirrorFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect mirror content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestReconcileOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	mirrorFileName := "mirror.json"
+	existingItems := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+
+	err := ioutil.WriteFile(fileName, []byte(existingItems), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(mirrorFileName, []byte("[]"), filePermission)
+	defer os.Remove(mirrorFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "reconcile", "mirror": mirrorFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(mirrorFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != existingItems {
+		t.Errorf("Expect mirror content to be '%s', but got '%s'", existingItems, bytes)
+	}
+}
+
+func TestMirrorMustDifferFromUsersFile(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "list", "mirror": "./" + fileName, "fileName": fileName}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
+
+func TestStrictMirrorFailure(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}",
+		"mirror": "missing/mirror.json", "mirrorMode": "strict", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeStorageIO {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeStorageIO, ErrorCodeOf(err))
+	}
+}
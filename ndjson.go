@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+const ndjsonFormat = "ndjson"
+
+// writeUsersNDJSON writes one JSON object per line, so that consumers can
+// process the users as they arrive.
+func writeUsersNDJSON(users []User, fields []computedField, writer io.Writer) error {
+	for _, user := range users {
+		userData, err := marshalUsers([]User{user}, fields)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(writer, "%s\n", userData[1:len(userData)-1])
+		if err != nil {
+			return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing users: %w", err), nil)
+		}
+	}
+	return nil
+}
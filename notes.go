@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	addNoteOp     = "addNote"
+	note          = "note"
+	hideNotes     = "hideNotes"
+	maxNoteLength = 1000
+)
+
+type Note struct {
+	At   string `json:"at"`
+	Text string `json:"text"`
+}
+
+func addUserNote(userId, noteArg string, storage *fileStorage, writer io.Writer) error {
+	text := sanitizeText(noteArg, false)
+	if len(text) == 0 {
+		return missingFlagError(note)
+	}
+	if utf8.RuneCountInString(text) > maxNoteLength {
+		return newOperationError(CodeValidation, fmt.Errorf("Note is longer than %d characters", maxNoteLength),
+			map[string]string{"flag": note, "limit": strconv.Itoa(maxNoteLength)})
+	}
+	return updateUserMeta(userId, storage, writer, func(user *User) {
+		user.Notes = append(user.Notes, Note{At: now().UTC().Format(time.RFC3339), Text: normalizeText(text)})
+	})
+}
+
+func stripNotes(users []User) {
+	for i := range users {
+		users[i].Notes = nil
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAddNoteOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	defaultClock := DefaultClock
+	DefaultClock = FixedClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	defer func() { DefaultClock = defaultClock }()
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "addNote", "id": "1", "note": "Disabled after chargeback", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "findById", "id": "1", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"notes\":[{\"at\":\"2024-05-01T12:00:00Z\",\"text\":\"Disabled after chargeback\"}]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	buffer.Reset()
+	err = Perform(Arguments{"operation": "findById", "id": "1", "hideNotes": "true", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput = "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestHideNotesKeepsNotesOnWrite(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"notes\":[{\"at\":\"2024-05-01T12:00:00Z\",\"text\":\"VIP\"}]}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "setMeta", "id": "1", "key": "team", "value": "sre", "hideNotes": "true", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"metadata\":{\"team\":\"sre\"},\"notes\":[{\"at\":\"2024-05-01T12:00:00Z\",\"text\":\"VIP\"}]}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestReadOnlyStorageCanNotSave(t *testing.T) {
+	storage, err := newFileStorage(context.Background(), Arguments{"fileName": fileName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.save([]User{})
+	if ErrorCodeOf(err) != CodeInternal {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeInternal, ErrorCodeOf(err))
+	}
+}
+
+func TestPatchHidesNotes(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"notes\":[{\"at\":\"2024-05-01T12:00:00Z\",\"text\":\"VIP\"}]}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "patch", "id": "1", "item": "{\"age\":35}", "hideNotes": "true", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":35}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+)
+
+const (
+	smtpAddr           = "smtpAddr"
+	smtpFrom           = "smtpFrom"
+	smtpUser           = "smtpUser"
+	smtpPassword       = "smtpPassword"
+	smtpPasswordEnv    = "USERS_SMTP_PASSWORD"
+	notifyAdmins       = "notifyAdmins"
+	notifyTemplate     = "notifyTemplate"
+	noNotify           = "noNotify"
+	inviteMailTemplate = `Subject: You are invited
+
+Hello {{.Email}},
+
+you have been invited. Use the token {{.Token}} to complete your registration before {{.ExpiresAt}}.
+`
+	inviteNoticeTemplate = `Subject: User invited
+
+{{.Email}} has been invited as user {{.Id}}. The invite expires at {{.ExpiresAt}}.
+`
+)
+
+var sendMail = smtp.SendMail
+
+type notifier struct {
+	addr     string
+	from     string
+	auth     smtp.Auth
+	admins   []string
+	template string
+}
+
+func newNotifier(args Arguments) (*notifier, error) {
+	addr := args[smtpAddr]
+	if len(addr) == 0 || args[noNotify] == "true" {
+		return nil, nil
+	}
+	if len(args[smtpFrom]) == 0 {
+		return nil, missingFlagError(smtpFrom)
+	}
+	n := &notifier{addr: addr, from: args[smtpFrom]}
+	if user := args[smtpUser]; len(user) > 0 {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, invalidFlagError(smtpAddr, addr)
+		}
+		n.auth = smtp.PlainAuth("", user, args[smtpPassword], host)
+	}
+	for _, admin := range strings.Split(args[notifyAdmins], ",") {
+		if admin = strings.TrimSpace(admin); len(admin) > 0 {
+			n.admins = append(n.admins, admin)
+		}
+	}
+	if templateFile := args[notifyTemplate]; len(templateFile) > 0 {
+		data, err := os.ReadFile(osPath(templateFile))
+		if err != nil {
+			return nil, newOperationError(CodeStorageIO, fmt.Errorf("Error while reading notification template: %w", err),
+				map[string]string{notifyTemplate: templateFile})
+		}
+		n.template = string(data)
+	}
+	return n, nil
+}
+
+// notifyInvite mails the invite with its token to the invitee alone, using
+// -notifyTemplate when given, and a notice without the token to the admins.
+func (n *notifier) notifyInvite(invite inviteResult) error {
+	if n == nil {
+		return nil
+	}
+	text := inviteMailTemplate
+	if len(n.template) > 0 {
+		text = n.template
+	}
+	err := n.notify(text, invite, []string{invite.Email})
+	if err != nil {
+		return err
+	}
+	invite.Token = ""
+	return n.notify(inviteNoticeTemplate, invite, n.admins)
+}
+
+func (n *notifier) notify(text string, data interface{}, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	mailTemplate, err := template.New("mail").Parse(text)
+	if err != nil {
+		return newOperationError(CodeValidation, fmt.Errorf("Error while parsing notification template: %w", err), nil)
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\n", n.from, strings.Join(recipients, ", "))
+	err = mailTemplate.Execute(&body, data)
+	if err != nil {
+		return newOperationError(CodeValidation, fmt.Errorf("Error while rendering notification template: %w", err), nil)
+	}
+	err = sendMail(n.addr, n.auth, n.from, recipients, body.Bytes())
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf("Error while sending notification: %w", err), nil)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/smtp"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInviteSendsNotification(t *testing.T) {
+	var buffer bytes.Buffer
+	var sentTo []string
+	var sentBodies []string
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentTo = append(sentTo, strings.Join(to, ","))
+		sentBodies = append(sentBodies, string(msg))
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+	defer os.Remove(fileName)
+
+	args := Arguments{
+		"operation":    "invite",
+		"email":        "new@test.com",
+		"smtpAddr":     "localhost:25",
+		"smtpFrom":     "noreply@test.com",
+		"notifyAdmins": "admin@test.com",
+		"fileName":     fileName,
+	}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(sentTo, ";") != "new@test.com;admin@test.com" {
+		t.Fatalf("Expect one mail to the user and one to the admins, but got %v", sentTo)
+	}
+	if !strings.Contains(sentBodies[0], "Subject: You are invited") {
+		t.Errorf("Expect invite template to be rendered, but got %s", sentBodies[0])
+	}
+	var result inviteResult
+	err = json.Unmarshal(buffer.Bytes(), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sentBodies[1], result.Token) {
+		t.Errorf("Expect admin notice not to contain the token, but got %s", sentBodies[1])
+	}
+}
+
+func TestInviteWarnsWhenNotificationFails(t *testing.T) {
+	var buffer, diagnostics bytes.Buffer
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		return errors.New("connection refused")
+	}
+	defer func() { sendMail = smtp.SendMail }()
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "invite", "email": "new@test.com", "smtpAddr": "localhost:25", "smtpFrom": "noreply@test.com", "fileName": fileName}
+	err := PerformTo(args, &buffer, &diagnostics)
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(buffer.String(), "\"token\"") {
+		t.Errorf("Expect invite to be printed, but got '%s'", buffer.String())
+	}
+	if !strings.Contains(diagnostics.String(), "connection refused") {
+		t.Errorf("Expect notification failure to be warned about, but got '%s'", diagnostics.String())
+	}
+}
+
+func TestNoNotifySuppressesNotification(t *testing.T) {
+	var buffer bytes.Buffer
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		t.Error("Expect no notification to be sent")
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "invite", "email": "new@test.com", "smtpAddr": "localhost:25", "smtpFrom": "noreply@test.com", "noNotify": "true", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	claimOp         = "claim"
+	releaseOp       = "release"
+	lease           = "lease"
+	ownerFileSuffix = ".owner"
+	defaultLease    = 24 * time.Hour
+	claimAttempts   = 3
+)
+
+var hostname = os.Hostname
+
+// ownership is the lease recorded in the <fileName>.owner file. While it has
+// not expired only its host may save the users file.
+type ownership struct {
+	Host    string    `json:"host"`
+	Expires time.Time `json:"expires"`
+}
+
+func readOwnership(fileName string) (*ownership, error) {
+	return readOwnerFile(osPath(fileName+ownerFileSuffix), fileName)
+}
+
+func readOwnerFile(path, fileName string) (*ownership, error) {
+	ownerData, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newOperationError(CodeStorageIO, fmt.Errorf("Error while reading owner file: %w", err),
+			map[string]string{userFileName: fileName})
+	}
+	var owner ownership
+	err = json.Unmarshal(ownerData, &owner)
+	if err != nil {
+		return nil, newOperationError(CodeInvalidData, fmt.Errorf("Owner file of %s is not valid: %w", fileName, err),
+			map[string]string{userFileName: fileName})
+	}
+	return &owner, nil
+}
+
+// otherOwner returns the lease of another host that has not expired yet.
+func otherOwner(fileName string) (*ownership, error) {
+	owner, err := readOwnership(fileName)
+	if err != nil || owner == nil {
+		return nil, err
+	}
+	host, err := currentHost()
+	if err != nil || !owner.heldElsewhere(host) {
+		return nil, err
+	}
+	return owner, nil
+}
+
+func (o *ownership) heldElsewhere(host string) bool {
+	return o != nil && o.Host != host && now().Before(o.Expires)
+}
+
+func currentHost() (string, error) {
+	host, err := hostname()
+	if err != nil {
+		return "", newOperationError(CodeInternal, fmt.Errorf("Error while reading host name: %w", err), nil)
+	}
+	return host, nil
+}
+
+func ownedError(fileName string, owner *ownership) error {
+	return newOperationError(CodeConflict, fmt.Errorf("Users file %s is owned by host %s", fileName, owner.Host),
+		map[string]string{userFileName: fileName, "owner": owner.Host})
+}
+
+func checkOwnership(fileName string) error {
+	owner, err := otherOwner(fileName)
+	if err != nil || owner == nil {
+		return err
+	}
+	return newOperationError(CodePermissionDenied,
+		fmt.Errorf("Users file %s is owned by host %s until %s and is read only here", fileName, owner.Host, owner.Expires.Format(time.RFC3339)),
+		map[string]string{userFileName: fileName, "owner": owner.Host})
+}
+
+// claimOwnership records this host as the only writer of the users file for
+// the -lease duration, renewing its own lease. A lease of another host has to
+// expire or be released first.
+func claimOwnership(leaseArg string, storage *fileStorage, writer io.Writer) error {
+	duration := defaultLease
+	if len(leaseArg) > 0 {
+		var err error
+		duration, err = time.ParseDuration(leaseArg)
+		if err != nil || duration <= 0 {
+			return invalidFlagError(lease, leaseArg)
+		}
+	}
+	host, err := currentHost()
+	if err != nil {
+		return err
+	}
+	ownerData, err := json.Marshal(ownership{Host: host, Expires: now().Add(duration).UTC()})
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	err = placeOwnerFile(storage.fileName, host, ownerData)
+	if err != nil {
+		return err
+	}
+	writer.Write(ownerData)
+	return nil
+}
+
+// placeOwnerFile hard links a complete copy of ownerData to the owner file,
+// which fails instead of overwriting when another claim got there first. A
+// lease of this host or an expired one is moved aside before the next attempt,
+// and the owner file is read back to confirm that the claim is still in place.
+func placeOwnerFile(fileName, host string, ownerData []byte) error {
+	ownerPath := osPath(fileName + ownerFileSuffix)
+	file, err := os.CreateTemp(filepath.Dir(ownerPath), "."+filepath.Base(ownerPath)+".*")
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing owner file: %w", err),
+			map[string]string{userFileName: fileName})
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(ownerData)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	for attempt := 1; err == nil; attempt++ {
+		err = os.Link(file.Name(), ownerPath)
+		if err == nil || !os.IsExist(err) {
+			break
+		}
+		if attempt == claimAttempts {
+			return newOperationError(CodeConflict, fmt.Errorf("Users file %s is being claimed by another host", fileName),
+				map[string]string{userFileName: fileName})
+		}
+		err = setAsideOwnerFile(fileName, host, file.Name()+".old")
+		if err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing owner file: %w", err),
+			map[string]string{userFileName: fileName})
+	}
+	placed, err := os.ReadFile(ownerPath)
+	if err == nil && !bytes.Equal(placed, ownerData) {
+		return newOperationError(CodeConflict, fmt.Errorf("Users file %s was claimed by another host", fileName),
+			map[string]string{userFileName: fileName})
+	}
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while reading owner file: %w", err),
+			map[string]string{userFileName: fileName})
+	}
+	return nil
+}
+
+// setAsideOwnerFile renames the current owner file away unless it holds a
+// lease of another host. Should a concurrent claim have replaced the lease
+// checked here, the renamed file is linked back and the claim fails.
+func setAsideOwnerFile(fileName, host, aside string) error {
+	owner, err := readOwnership(fileName)
+	if err != nil {
+		return err
+	}
+	if owner.heldElsewhere(host) {
+		return ownedError(fileName, owner)
+	}
+	ownerPath := osPath(fileName + ownerFileSuffix)
+	err = os.Rename(ownerPath, aside)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while replacing owner file: %w", err),
+			map[string]string{userFileName: fileName})
+	}
+	defer os.Remove(aside)
+	owner, err = readOwnerFile(aside, fileName)
+	if err == nil && owner.heldElsewhere(host) {
+		os.Link(aside, ownerPath)
+		return ownedError(fileName, owner)
+	}
+	return nil
+}
+
+// releaseOwnership removes the lease of this host, or an expired one.
+func releaseOwnership(storage *fileStorage, writer io.Writer) error {
+	err := checkOwnership(storage.fileName)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(osPath(storage.fileName + ownerFileSuffix))
+	if err != nil && !os.IsNotExist(err) {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while removing owner file: %w", err),
+			map[string]string{userFileName: storage.fileName})
+	}
+	fmt.Fprintf(writer, "Users file %s released", storage.fileName)
+	return nil
+}
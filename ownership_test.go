@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOwnershipLease(t *testing.T) {
+	var buffer bytes.Buffer
+	defer func() { hostname = os.Hostname }()
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(fileName + ".owner")
+
+	hostname = func() (string, error) { return "cron-a", nil }
+	err = Perform(Arguments{"operation": "claim", "lease": "1h", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	hostname = func() (string, error) { return "cron-b", nil }
+	err = Perform(Arguments{"operation": "add", "item": "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodePermissionDenied {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodePermissionDenied, ErrorCodeOf(err))
+	}
+	err = Perform(Arguments{"operation": "claim", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, ErrorCodeOf(err))
+	}
+
+	hostname = func() (string, error) { return "cron-a", nil }
+	err = Perform(Arguments{"operation": "add", "item": "{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	err = Perform(Arguments{"operation": "release", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err = os.Stat(fileName + ".owner"); !os.IsNotExist(err) {
+		t.Errorf("Expect owner file to be removed, but got %v", err)
+	}
+}
+
+func TestClaimLeavesOtherOwnerFile(t *testing.T) {
+	var buffer bytes.Buffer
+	defer func() { hostname = os.Hostname }()
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(fileName + ".owner")
+	ownerData := "{\"host\":\"cron-b\",\"expires\":\"" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + "\"}"
+	err = ioutil.WriteFile(fileName+".owner", []byte(ownerData), filePermission)
+	if err != nil {
+		t.Error(err)
+	}
+
+	hostname = func() (string, error) { return "cron-a", nil }
+	err = Perform(Arguments{"operation": "claim", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, ErrorCodeOf(err))
+	}
+	file, err := ioutil.ReadFile(fileName + ".owner")
+	if err != nil {
+		t.Error(err)
+	}
+	if string(file) != ownerData {
+		t.Errorf("Expect owner file to be '%s', but got '%s'", ownerData, file)
+	}
+
+	ownerData = "{\"host\":\"cron-b\",\"expires\":\"" + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) + "\"}"
+	err = ioutil.WriteFile(fileName+".owner", []byte(ownerData), filePermission)
+	if err != nil {
+		t.Error(err)
+	}
+	buffer.Reset()
+	err = Perform(Arguments{"operation": "claim", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	file, err = ioutil.ReadFile(fileName + ".owner")
+	if err != nil {
+		t.Error(err)
+	}
+	if string(file) != buffer.String() {
+		t.Errorf("Expect owner file to be '%s', but got '%s'", buffer.String(), file)
+	}
+}
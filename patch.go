@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const patchOp = "patch"
+
+// patchUser applies item to the user with idArg using JSON merge patch
+// semantics (RFC 7396): provided fields replace, null fields are removed and
+// nested objects are merged.
+func patchUser(item, idArg, countryArg string, storage *fileStorage, writer io.Writer) error {
+	var patch map[string]interface{}
+	err := json.Unmarshal([]byte(item), &patch)
+	if err != nil {
+		return newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+	}
+	if patchId, ok := patch[id]; ok && patchId != idArg {
+		return newOperationError(CodeValidation, fmt.Errorf("Item id can not be changed by %s", patchOp),
+			map[string]string{id: idArg})
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	for i, user := range users {
+		if user.Id != idArg {
+			continue
+		}
+		userData, err := json.Marshal(user)
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		var fields map[string]interface{}
+		err = json.Unmarshal(userData, &fields)
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+		userData, err = json.Marshal(mergePatch(fields, patch))
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		var patchedUser User
+		err = json.Unmarshal(userData, &patchedUser)
+		if err != nil {
+			return newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+		patchedUser.Id = idArg
+		err = normalizeUser(&patchedUser, countryArg)
+		if err != nil {
+			return err
+		}
+		users[i] = patchedUser
+		err = storage.save(users)
+		if err != nil {
+			return err
+		}
+		userData, err = json.Marshal(storage.shown([]User{patchedUser})[0])
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		writer.Write(userData)
+		return nil
+	}
+	storage.diagnostics.Write([]byte(fmt.Sprintf(userNotFoundMsg, idArg)))
+	return nil
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchFields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetFields, ok := target.(map[string]interface{})
+	if !ok {
+		targetFields = map[string]interface{}{}
+	}
+	for key, value := range patchFields {
+		if value == nil {
+			delete(targetFields, key)
+			continue
+		}
+		targetFields[key] = mergePatch(targetFields[key], value)
+	}
+	return targetFields
+}
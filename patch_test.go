@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPatchOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"5\",\"email\":\"test@test.com\",\"age\":30,\"address\":{\"city\":\"Kyiv\",\"country\":\"UA\"},\"metadata\":{\"team\":\"a\",\"role\":\"dev\"}}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "patch", "id": "5", "item": "{\"age\":31,\"address\":{\"city\":\"Lviv\"},\"metadata\":{\"role\":null}}", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "{\"id\":\"5\",\"email\":\"test@test.com\",\"age\":31,\"address\":{\"city\":\"Lviv\",\"country\":\"UA\"},\"metadata\":{\"team\":\"a\"}}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestPatchOperationWrongID(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"5\",\"email\":\"test@test.com\",\"age\":30}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "patch", "id": "6", "item": "{\"age\":31}", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "Item with id 6 not found"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
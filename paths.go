@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// samePath reports whether both names refer to the same file, also when they
+// are spelled differently, for example with another case on Windows.
+func samePath(a, b string) bool {
+	aInfo, aErr := os.Stat(osPath(a))
+	bInfo, bErr := os.Stat(osPath(b))
+	if aErr == nil && bErr == nil {
+		return os.SameFile(aInfo, bInfo)
+	}
+	aAbs, aErr := filepath.Abs(a)
+	bAbs, bErr := filepath.Abs(b)
+	if aErr != nil || bErr != nil {
+		return a == b
+	}
+	return pathsEqual(aAbs, bAbs)
+}
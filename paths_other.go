@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+func osPath(name string) string {
+	return name
+}
+
+func pathsEqual(a, b string) bool {
+	return a == b
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const longPathPrefix = `\\?\`
+
+// osPath makes name absolute so that the os package adds the \\?\ prefix to
+// paths over MAX_PATH, including UNC shares. Paths already carrying the prefix
+// are passed through untouched.
+func osPath(name string) string {
+	if len(name) == 0 || strings.HasPrefix(name, longPathPrefix) {
+		return name
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	return abs
+}
+
+func pathsEqual(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, longPathPrefix), strings.TrimPrefix(b, longPathPrefix))
+}
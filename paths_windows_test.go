@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLongPathUsersFile(t *testing.T) {
+	var buffer bytes.Buffer
+	dir := t.TempDir()
+	longDir := filepath.Join(dir, strings.Repeat("a", 120), strings.Repeat("b", 120))
+	err := os.MkdirAll(osPath(longDir), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	longFileName := filepath.Join(longDir, fileName)
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}", "fileName": longFileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := os.ReadFile(osPath(longFileName))
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestPathsDifferingInCaseAreSame(t *testing.T) {
+	if !samePath(`C:\Users\Test\users.json`, `c:\users\test\USERS.json`) {
+		t.Error("Expect paths differing in case to be the same file")
+	}
+	if osPath(`\\?\C:\users.json`) != `\\?\C:\users.json` {
+		t.Errorf("Expect prefixed path to be kept, but got '%s'", osPath(`\\?\C:\users.json`))
+	}
+}
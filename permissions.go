@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	profile            = "profile"
+	permissionsSection = "permissions"
+	anyOperation       = "*"
+	profileEnv         = "USERS_PROFILE"
+)
+
+// checkPermission enforces the [permissions] section of the config file,
+// which maps a profile to the comma separated operations it may run.
+// Invocations without -profile are not restricted.
+func checkPermission(args Arguments) error {
+	profileArg := args[profile]
+	if len(profileArg) == 0 {
+		return nil
+	}
+	settings, err := loadConfig(args[configFile])
+	if err != nil {
+		return err
+	}
+	allowed, ok := settings[permissionsSection][profileArg]
+	if ok {
+		for _, allowedOp := range strings.Split(allowed, ",") {
+			allowedOp = strings.TrimSpace(allowedOp)
+			if allowedOp == anyOperation || allowedOp == args[operation] {
+				return nil
+			}
+		}
+	}
+	return newOperationError(CodePermissionDenied,
+		fmt.Errorf("Operation %s is not permitted for profile %s", args[operation], profileArg),
+		map[string]string{operation: args[operation], profile: profileArg})
+}
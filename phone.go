@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	phone          = "phone"
+	defaultCountry = "defaultCountry"
+	findByPhoneOp  = "findByPhone"
+)
+
+var e164Format = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+var callingCodes = map[string]string{
+	"AT": "43", "AU": "61", "BE": "32", "BR": "55", "BY": "375", "CA": "1", "CH": "41", "CN": "86",
+	"CZ": "420", "DE": "49", "DK": "45", "EE": "372", "ES": "34", "FI": "358", "FR": "33", "GB": "44",
+	"GE": "995", "IE": "353", "IL": "972", "IN": "91", "IT": "39", "JP": "81", "KZ": "7", "LT": "370",
+	"LV": "371", "MD": "373", "MX": "52", "NL": "31", "NO": "47", "NZ": "64", "PL": "48", "PT": "351",
+	"RO": "40", "SE": "46", "SK": "421", "TR": "90", "UA": "380", "US": "1", "UZ": "998",
+}
+
+func validateDefaultCountry(country string) error {
+	if _, ok := callingCodes[strings.ToUpper(country)]; len(country) > 0 && !ok {
+		return invalidFlagError(defaultCountry, country)
+	}
+	return nil
+}
+
+func normalizePhone(value, country string) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	var digits strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+	trimmed := strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(trimmed, "+"):
+		number = "+" + number
+	case strings.HasPrefix(number, "00"):
+		number = "+" + strings.TrimPrefix(number, "00")
+	case len(country) > 0:
+		number = "+" + callingCodes[strings.ToUpper(country)] + strings.TrimPrefix(number, "0")
+	}
+	if !e164Format.MatchString(number) {
+		return "", newOperationError(CodeValidation, fmt.Errorf("Phone %s is not a valid E.164 number", value),
+			map[string]string{phone: value})
+	}
+	return number, nil
+}
+
+func findUserByPhone(phoneArg, country string, storage *fileStorage, writer io.Writer) error {
+	number, err := normalizePhone(phoneArg, country)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.Phone == number {
+			userData, err := json.Marshal(user)
+			if err != nil {
+				return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+			}
+			writer.Write(userData)
+			return nil
+		}
+	}
+	writer.Write([]byte(""))
+	return nil
+}
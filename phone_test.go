@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	cases := map[string]string{
+		"+1 (555) 010-9999": "+15550109999",
+		"0044 20 7946 0958": "+442079460958",
+		"067 123 45 67":     "+380671234567",
+	}
+	for value, expected := range cases {
+		number, err := normalizePhone(value, "UA")
+		if err != nil {
+			t.Error(err)
+		}
+		if number != expected {
+			t.Errorf("Expect %s to be normalized to %s, but got %s", value, expected, number)
+		}
+	}
+}
+
+func TestNormalizePhoneInvalid(t *testing.T) {
+	_, err := normalizePhone("12345", "")
+
+	if code := ErrorCodeOf(err); code != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, code)
+	}
+}
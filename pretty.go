@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	pretty       = "pretty"
+	prettyIndent = "  "
+)
+
+// writeJSON writes marshalled JSON, indented with -pretty.
+func writeJSON(jsonData []byte, prettyArg bool, writer io.Writer) error {
+	if !prettyArg {
+		writer.Write(jsonData)
+		return nil
+	}
+	indented, err := json.MarshalIndent(json.RawMessage(jsonData), "", prettyIndent)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(indented)
+	return nil
+}
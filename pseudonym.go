@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	pseudonymizeOp   = "pseudonymize"
+	depseudonymizeOp = "depseudonymize"
+	pseudonymKey     = "pseudonymKey"
+	pseudonymKeyEnv  = "USERS_PSEUDONYM_KEY"
+	mappingFile      = "mappingFile"
+	pseudonymPrefix  = "pseudo:"
+)
+
+func pseudonymizeUsers(args Arguments, storage *fileStorage, writer io.Writer) error {
+	keyArg := args[pseudonymKey]
+	if len(keyArg) == 0 {
+		return missingFlagError(pseudonymKey)
+	}
+	mappingFileArg := args[mappingFile]
+	if len(mappingFileArg) == 0 {
+		return missingFlagError(mappingFile)
+	}
+	mapping, err := loadPseudonymMapping(mappingFileArg)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	count := 0
+	for i, user := range users {
+		if len(user.Email) == 0 || strings.HasPrefix(user.Email, pseudonymPrefix) {
+			continue
+		}
+		token := pseudonymToken(keyArg, user.Email)
+		mapping[token] = user.Email
+		users[i].Email, users[i].EmailAscii = token, ""
+		count++
+	}
+	err = savePseudonymMapping(mapping, mappingFileArg)
+	if err != nil {
+		return err
+	}
+	err = storage.save(users)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "%d emails pseudonymized", count)
+	return nil
+}
+
+func depseudonymizeUsers(args Arguments, storage *fileStorage, writer io.Writer) error {
+	mappingFileArg := args[mappingFile]
+	if len(mappingFileArg) == 0 {
+		return missingFlagError(mappingFile)
+	}
+	mapping, err := loadPseudonymMapping(mappingFileArg)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	count := 0
+	for i, user := range users {
+		if !strings.HasPrefix(user.Email, pseudonymPrefix) {
+			continue
+		}
+		email, ok := mapping[user.Email]
+		if !ok {
+			return newOperationError(CodeNotFound, fmt.Errorf("No mapping found for token of user %s", user.Id),
+				map[string]string{id: user.Id})
+		}
+		users[i].Email = email
+		users[i].EmailAscii, err = idnEmail(email)
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	err = storage.save(users)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "%d emails restored", count)
+	return nil
+}
+
+func pseudonymToken(key, email string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.ToLower(email)))
+	return pseudonymPrefix + hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+func loadPseudonymMapping(mappingFileName string) (map[string]string, error) {
+	mapping := map[string]string{}
+	data, err := os.ReadFile(osPath(mappingFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return mapping, nil
+	}
+	if err != nil {
+		return nil, newOperationError(CodeStorageIO, fmt.Errorf("Error while reading mapping file: %w", err),
+			map[string]string{mappingFile: mappingFileName})
+	}
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &mapping)
+		if err != nil {
+			return nil, newOperationError(CodeInvalidData, fmt.Errorf("Error to unmarshal mapping file: %w", err),
+				map[string]string{mappingFile: mappingFileName})
+		}
+	}
+	return mapping, nil
+}
+
+func savePseudonymMapping(mapping map[string]string, mappingFileName string) error {
+	data, err := json.Marshal(mapping)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	err = os.WriteFile(osPath(mappingFileName), data, 0600)
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing mapping file: %w", err),
+			map[string]string{mappingFile: mappingFileName})
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizeRoundTrip(t *testing.T) {
+	var buffer bytes.Buffer
+	mappingFileName := "mapping.json"
+	existingItems := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+
+	err := ioutil.WriteFile(fileName, []byte(existingItems), filePermission)
+	defer os.Remove(fileName)
+	defer os.Remove(mappingFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "pseudonymize", "pseudonymKey": "secret", "mappingFile": mappingFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if strings.Contains(string(bytes), "test@test.com") {
+		t.Errorf("Expect email to be replaced with a token, but got %s", bytes)
+	}
+
+	args = Arguments{"operation": "depseudonymize", "mappingFile": mappingFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err = ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != existingItems {
+		t.Errorf("Expect file content to be '%s', but got '%s'", existingItems, bytes)
+	}
+}
+
+func TestPseudonymizeReplacesAsciiEmail(t *testing.T) {
+	var buffer bytes.Buffer
+	mappingFileName := "mapping.json"
+	existingItems := "[{\"id\":\"1\",\"email\":\"jose@bücher.de\",\"emailAscii\":\"jose@xn--bcher-kva.de\",\"age\":34}]"
+
+	err := ioutil.WriteFile(fileName, []byte(existingItems), filePermission)
+	defer os.Remove(fileName)
+	defer os.Remove(mappingFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "pseudonymize", "pseudonymKey": "secret", "mappingFile": mappingFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if strings.Contains(string(bytes), "jose@") {
+		t.Errorf("Expect both emails to be replaced with a token, but got %s", bytes)
+	}
+
+	args = Arguments{"operation": "depseudonymize", "mappingFile": mappingFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, err = ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != existingItems {
+		t.Errorf("Expect file content to be '%s', but got '%s'", existingItems, bytes)
+	}
+}
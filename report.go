@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	reportOp            = "report"
+	reportName          = "report"
+	reportSectionPrefix = "reports."
+	reportFields        = "fields"
+)
+
+// runReport runs a named report from a [reports.<name>] section of the config
+// file, which may set a filter, the fields to keep, the json or csv format and
+// the output file, for example:
+//
+//	[reports.weekly-minors-check]
+//	filter = "age < 18"
+//	fields = "id, email, age"
+//	format = "csv"
+//	output = "minors.csv"
+//
+// Scheduling is left to cron or a similar scheduler calling the report.
+func runReport(args Arguments, storage *fileStorage, writer io.Writer) error {
+	nameArg := args[reportName]
+	if len(nameArg) == 0 {
+		return missingFlagError(reportName)
+	}
+	settings, err := loadConfig(args[configFile])
+	if err != nil {
+		return err
+	}
+	report, ok := settings[reportSectionPrefix+nameArg]
+	if !ok {
+		return newOperationError(CodeNotFound, fmt.Errorf("Report %s is not defined in config file %s", nameArg, args[configFile]),
+			map[string]string{reportName: nameArg, configFile: args[configFile]})
+	}
+	switch report[format] {
+	case "", jsonFormat, csvFormat:
+	default:
+		return reportError(nameArg, format, report[format])
+	}
+	var match predicate
+	if len(report[filter]) > 0 {
+		match, err = parseFilter(report[filter])
+		if err != nil {
+			return reportError(nameArg, filter, report[filter])
+		}
+	}
+	var columns []string
+	for _, column := range strings.Split(report[reportFields], ",") {
+		if column = strings.TrimSpace(column); len(column) > 0 {
+			if _, ok := userFieldValue(User{}, column); !ok {
+				return reportError(nameArg, reportFields, report[reportFields])
+			}
+			columns = append(columns, column)
+		}
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	if match != nil {
+		users = filterUsers(users, match)
+	}
+
+	var reportData bytes.Buffer
+	switch {
+	case len(columns) == 0:
+		err = writeUsersAs(report[format], "", users, nil, &reportData)
+	case report[format] == csvFormat:
+		err = writeReportCSV(users, columns, &reportData)
+	default:
+		err = writeReportJSON(users, columns, &reportData)
+	}
+	if err != nil {
+		return err
+	}
+	if len(report[output]) == 0 {
+		writer.Write(reportData.Bytes())
+		return nil
+	}
+	err = os.WriteFile(osPath(report[output]), reportData.Bytes(), 0644)
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing report file: %w", err),
+			map[string]string{reportName: nameArg, output: report[output]})
+	}
+	fmt.Fprintf(writer, "%d users written to %s", len(users), report[output])
+	return nil
+}
+
+func reportError(nameArg, key, value string) error {
+	return newOperationError(CodeValidation, fmt.Errorf("Report %s has an invalid %s %s", nameArg, key, value),
+		map[string]string{reportName: nameArg, key: value})
+}
+
+// writeReportJSON keeps only the columns of every user, with the values the
+// filter compares.
+func writeReportJSON(users []User, columns []string, writer io.Writer) error {
+	rows := make([]map[string]string, 0, len(users))
+	for _, user := range users {
+		row := make(map[string]string, len(columns))
+		for _, column := range columns {
+			row[column], _ = userFieldValue(user, column)
+		}
+		rows = append(rows, row)
+	}
+	rowsData, err := json.Marshal(rows)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(rowsData)
+	return nil
+}
+
+func writeReportCSV(users []User, columns []string, writer io.Writer) error {
+	records := csv.NewWriter(writer)
+	err := records.Write(columns)
+	for _, user := range users {
+		if err != nil {
+			break
+		}
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			value, _ := userFieldValue(user, column)
+			row = append(row, value)
+		}
+		err = records.Write(row)
+	}
+	if err == nil {
+		records.Flush()
+		err = records.Error()
+	}
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing CSV: %w", err), nil)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReportOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+	reportFileName := "minors.csv"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":16},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("[reports.weekly-minors-check]\nfilter = \"age < 18\"\nfields = \"id, age\"\nformat = \"csv\"\noutput = \"minors.csv\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(reportFileName)
+
+	err = Perform(Arguments{"operation": "report", "report": "weekly-minors-check", "config": configFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "1 users written to minors.csv"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(reportFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "id,age\n1,16\n"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestReportOperationUnknownReport(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(configFileName, []byte("[reports.weekly]\nfilter = \"age < 18\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(fileName)
+
+	err = Perform(Arguments{"operation": "report", "report": "daily", "config": configFileName, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeNotFound {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeNotFound, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	rules              = "rules"
+	rulesSectionPrefix = "rules."
+)
+
+// validationRule is a filter condition every user has to match.
+type validationRule struct {
+	pack      string
+	name      string
+	condition string
+	match     predicate
+}
+
+// embeddedRulePacks are the rule packs built into the tool. Further packs are
+// loaded from [rules.<pack>] sections of the config file, one named condition
+// per line, for example:
+//
+//	[rules.corp-email]
+//	corpDomain = "domain == 'corp.example.com'"
+var embeddedRulePacks = map[string]map[string]string{
+	"adult-only":  {"adult": "age >= 18"},
+	"has-phone":   {"phone": "phone != ''"},
+	"has-address": {"country": "country != ''", "city": "city != ''"},
+}
+
+// loadRules resolves the comma-separated -rules pack names, preferring a
+// config pack over an embedded one of the same name.
+func loadRules(args Arguments) ([]validationRule, error) {
+	if len(args[rules]) == 0 {
+		return nil, nil
+	}
+	var settings config
+	if len(args[configFile]) > 0 {
+		var err error
+		settings, err = loadConfig(args[configFile])
+		if err != nil {
+			return nil, err
+		}
+	}
+	var loaded []validationRule
+	for _, pack := range strings.Split(args[rules], ",") {
+		pack = strings.TrimSpace(pack)
+		conditions, ok := settings[rulesSectionPrefix+pack]
+		if !ok {
+			conditions, ok = embeddedRulePacks[pack]
+		}
+		if !ok {
+			return nil, invalidFlagError(rules, pack)
+		}
+		names := make([]string, 0, len(conditions))
+		for name := range conditions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			match, err := parseFilter(conditions[name])
+			if err != nil {
+				return nil, newOperationError(CodeValidation, fmt.Errorf("Rule %s of pack %s has an invalid condition", name, pack),
+					map[string]string{rules: pack, "rule": name})
+			}
+			loaded = append(loaded, validationRule{pack: pack, name: name, condition: conditions[name], match: match})
+		}
+	}
+	return loaded, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestValidateOperationWithRulePacks(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@corp.com\",\"age\":34},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":17}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("[rules.corp-email]\ncorpDomain = \"domain == 'corp.com'\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "validate", "rules": "corp-email,adult-only", "config": configFileName, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeInvalidData {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeInvalidData, ErrorCodeOf(err))
+	}
+
+	expectedOutput := "{\"valid\":false,\"users\":2,\"problems\":[" +
+		"{\"index\":1,\"id\":\"2\",\"field\":\"rules\",\"problem\":\"corp-email/corpDomain fails domain == 'corp.com'\"}," +
+		"{\"index\":1,\"id\":\"2\",\"field\":\"rules\",\"problem\":\"adult-only/adult fails age \\u003e= 18\"}]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestValidateOperationUnknownRulePack(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	err := Perform(Arguments{"operation": "validate", "rules": "eu-ids", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+const (
+	sampleOp = "sample"
+	number   = "n"
+	seed     = "seed"
+)
+
+// sampleUsers writes n users picked at random, in file order. The same -seed
+// picks the same users from the same file.
+func sampleUsers(sizeArg, seedArg string, storage *fileStorage, writer io.Writer) error {
+	if len(sizeArg) == 0 {
+		return missingFlagError(number)
+	}
+	size, err := strconv.Atoi(sizeArg)
+	if err != nil || size < 0 {
+		return invalidFlagError(number, sizeArg)
+	}
+	seedValue := now().UnixNano()
+	if len(seedArg) > 0 {
+		seedValue, err = strconv.ParseInt(seedArg, 10, 64)
+		if err != nil {
+			return invalidFlagError(seed, seedArg)
+		}
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	picked := rand.New(rand.NewSource(seedValue)).Perm(len(users))
+	if size < len(picked) {
+		picked = picked[:size]
+	}
+	sort.Ints(picked)
+	sampled := make([]User, 0, len(picked))
+	for _, i := range picked {
+		sampled = append(sampled, users[i])
+	}
+	usersData, err := json.Marshal(sampled)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(usersData)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSampleOperationWithSeed(t *testing.T) {
+	var first, second bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":21},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":23},{\"id\":\"4\",\"email\":\"test4@test.com\",\"age\":24}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "sample", "n": "2", "seed": "42", "fileName": fileName}
+	err = Perform(args, &first)
+	if err != nil {
+		t.Error(err)
+	}
+	err = Perform(args, &second)
+	if err != nil {
+		t.Error(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("Expect output to be '%s', but got '%s'", first.String(), second.String())
+	}
+	if count := bytes.Count(first.Bytes(), []byte("\"id\"")); count != 2 {
+		t.Errorf("Expect 2 users in output, but got '%s'", first.String())
+	}
+}
+
+func TestSampleOperationLargerThanFile(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":21},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "sample", "n": "10", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"test1@test.com\",\"age\":21},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":22}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestSampleOperationWrongSize(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	err := Perform(Arguments{"operation": "sample", "n": "few", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	sanitizeOp      = "sanitize"
+	sanitizedResult = "sanitized"
+)
+
+// sanitizeText strips control characters and trims the text. Free text such
+// as city names also gets runs of inner whitespace collapsed to one space.
+func sanitizeText(text string, collapse bool) string {
+	text = strings.Map(func(r rune) rune {
+		if (unicode.IsControl(r) && !unicode.IsSpace(r)) || r == '\u200b' || r == '\ufeff' {
+			return -1
+		}
+		return r
+	}, text)
+	if collapse {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	return strings.TrimSpace(text)
+}
+
+func sanitizeUser(user *User) {
+	user.Id = sanitizeText(user.Id, false)
+	user.Email = strings.Join(strings.Fields(sanitizeText(user.Email, false)), "")
+	user.Phone = sanitizeText(user.Phone, true)
+	user.Dob = sanitizeText(user.Dob, false)
+	user.Status = sanitizeText(user.Status, false)
+	if user.Address != nil {
+		user.Address.Street = sanitizeText(user.Address.Street, true)
+		user.Address.City = sanitizeText(user.Address.City, true)
+		user.Address.Country = sanitizeText(user.Address.Country, false)
+	}
+	for metaKey, metaValue := range user.Metadata {
+		user.Metadata[metaKey] = sanitizeText(metaValue, true)
+	}
+}
+
+func sanitizeUsers(storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	results := []recordResult{}
+	for i := range users {
+		before, err := json.Marshal(users[i])
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		sanitizeUser(&users[i])
+		normalizeUserText(&users[i])
+		after, err := json.Marshal(users[i])
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		if string(before) != string(after) {
+			results = append(results, recordResult{Id: users[i].Id, Result: sanitizedResult})
+		}
+	}
+	if len(results) > 0 {
+		err = storage.save(users)
+		if err != nil {
+			return err
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
+}
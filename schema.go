@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	gofmt "go/format"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	schemaOp         = "schema"
+	jsonSchemaFormat = "jsonschema"
+	goFormat         = "go"
+	typescriptFormat = "typescript"
+)
+
+type schemaField struct {
+	name     string
+	optional bool
+	field    reflect.StructField
+}
+
+func writeSchema(formatArg string, writer io.Writer) error {
+	userType := reflect.TypeOf(User{})
+	switch formatArg {
+	case "", jsonSchemaFormat:
+		schema := jsonSchemaOf(userType)
+		schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+		schema["title"] = userType.Name()
+		schemaData, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		writer.Write(schemaData)
+	case goFormat:
+		var source bytes.Buffer
+		fmt.Fprint(&source, "package users\n")
+		for _, structType := range namedStructs(userType) {
+			fmt.Fprintf(&source, "\ntype %s struct {\n", structType.Name())
+			for _, f := range schemaFields(structType) {
+				fmt.Fprintf(&source, "\t%s %s `json:\"%s\"`\n", f.field.Name, goTypeName(f.field.Type), f.field.Tag.Get("json"))
+			}
+			fmt.Fprint(&source, "}\n")
+		}
+		formatted, err := gofmt.Source(source.Bytes())
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf("Error while formatting Go schema: %w", err), nil)
+		}
+		writer.Write(formatted)
+	case typescriptFormat:
+		for i, structType := range namedStructs(userType) {
+			if i > 0 {
+				fmt.Fprint(writer, "\n")
+			}
+			fmt.Fprintf(writer, "export interface %s {\n", structType.Name())
+			for _, f := range schemaFields(structType) {
+				optional := ""
+				if f.optional {
+					optional = "?"
+				}
+				fmt.Fprintf(writer, "  %s%s: %s;\n", f.name, optional, typescriptTypeName(f.field.Type))
+			}
+			fmt.Fprint(writer, "}\n")
+		}
+	default:
+		return invalidFlagError(format, formatArg)
+	}
+	return nil
+}
+
+func schemaFields(structType reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < structType.NumField(); i++ {
+		f := structType.Field(i)
+		name, options, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || len(name) == 0 {
+			continue
+		}
+		fields = append(fields, schemaField{name: name, optional: strings.Contains(options, "omitempty"), field: f})
+	}
+	return fields
+}
+
+func namedStructs(root reflect.Type) []reflect.Type {
+	structs := []reflect.Type{root}
+	seen := map[reflect.Type]bool{root: true}
+	for i := 0; i < len(structs); i++ {
+		for _, f := range schemaFields(structs[i]) {
+			fieldType := f.field.Type
+			for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && !seen[fieldType] {
+				seen[fieldType] = true
+				structs = append(structs, fieldType)
+			}
+		}
+	}
+	return structs
+}
+
+func jsonSchemaOf(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonSchemaOf(t.Elem())
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, f := range schemaFields(t) {
+			properties[f.name] = jsonSchemaOf(f.field.Type)
+			if !f.optional {
+				required = append(required, f.name)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem())}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": jsonSchemaOf(t.Elem())}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+func goTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + goTypeName(t.Elem())
+	case reflect.Map:
+		return "map[" + goTypeName(t.Key()) + "]" + goTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + goTypeName(t.Elem())
+	default:
+		return t.Name()
+	}
+}
+
+func typescriptTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typescriptTypeName(t.Elem())
+	case reflect.Struct:
+		return t.Name()
+	case reflect.Map:
+		return "Record<string, " + typescriptTypeName(t.Elem()) + ">"
+	case reflect.Slice:
+		return typescriptTypeName(t.Elem()) + "[]"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	default:
+		return "number"
+	}
+}
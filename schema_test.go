@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSchemaTypescript(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := Perform(Arguments{"operation": "schema", "format": "typescript"}, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"export interface User {", "  id: string;", "  age: number;", "  address?: Address;", "export interface Address {"} {
+		if !strings.Contains(buffer.String(), expected) {
+			t.Errorf("Expect schema to contain '%s', but got '%s'", expected, buffer.String())
+		}
+	}
+}
+
+func TestSchemaJSONRequiredFields(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := Perform(Arguments{"operation": "schema"}, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\"required\": [\n    \"id\",\n    \"email\",\n    \"age\"\n  ]"
+	if !strings.Contains(buffer.String(), expected) {
+		t.Errorf("Expect schema to contain '%s', but got '%s'", expected, buffer.String())
+	}
+}
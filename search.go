@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	searchOp = "search"
+	q        = "q"
+	query    = "query"
+)
+
+// searchUsers matches words of -q against the start of words in any field,
+// or with -query finds the text anywhere in the id or the email.
+func searchUsers(qArg, queryArg string, storage *fileStorage, writer io.Writer) error {
+	terms := tokenize(qArg)
+	text := foldText(queryArg)
+	if len(terms) == 0 && len(text) == 0 {
+		return newOperationError(CodeValidation, fmt.Errorf("-%s or -%s flag has to be specified", q, query),
+			map[string]string{"flag": q + "," + query})
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	found := []User{}
+	for _, user := range users {
+		if len(text) > 0 && !strings.Contains(foldText(user.Id), text) && !strings.Contains(foldText(user.Email), text) {
+			continue
+		}
+		if len(terms) == 0 || matchesAllTerms(searchTokens(user), terms) {
+			found = append(found, user)
+		}
+	}
+	usersData, err := json.Marshal(found)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(usersData)
+	return nil
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(foldText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func searchTokens(user User) []string {
+	fields := []string{user.Id, user.Email, user.Phone}
+	if user.Address != nil {
+		fields = append(fields, user.Address.Street, user.Address.City, user.Address.Country)
+	}
+	for metaKey, metaValue := range user.Metadata {
+		fields = append(fields, metaKey, metaValue)
+	}
+	return tokenize(strings.Join(fields, " "))
+}
+
+func matchesAllTerms(tokens, terms []string) bool {
+	for _, term := range terms {
+		matched := false
+		for _, token := range tokens {
+			if strings.HasPrefix(token, term) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSearchOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"john.smith@test.com\",\"age\":34,\"metadata\":{\"team\":\"SRE\"}},{\"id\":\"2\",\"email\":\"jane@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"john.smith@test.com\",\"age\":34,\"metadata\":{\"team\":\"SRE\"}}]"
+	args := Arguments{"operation": "search", "q": "Smith sre", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestSearchOperationSubstring(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"john.blacksmith@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"jane@test.com\",\"age\":31}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"john.blacksmith@test.com\",\"age\":34}]"
+	args := Arguments{"operation": "search", "query": "SMITH", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestSearchOperationMissingQuery(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	expectedError := "-q or -query flag has to be specified"
+	err := Perform(Arguments{"operation": "search", "fileName": fileName}, &buffer)
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expect error to be '%s', but got '%v'", expectedError, err)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	set          = "set"
+	setSeparator = "\n"
+)
+
+type multiValueFlag []string
+
+func (f *multiValueFlag) String() string {
+	return strings.Join(*f, setSeparator)
+}
+
+func (f *multiValueFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func applySetArgs(itemArg, setArg string) (string, error) {
+	if len(setArg) == 0 {
+		return itemArg, nil
+	}
+	fields := map[string]interface{}{}
+	if len(itemArg) > 0 {
+		err := json.Unmarshal([]byte(itemArg), &fields)
+		if err != nil {
+			return "", newOperationError(CodeValidation, fmt.Errorf(unmarshalingErrorMsg, err), nil)
+		}
+	}
+	for _, assignment := range strings.Split(setArg, setSeparator) {
+		key, value, ok := strings.Cut(assignment, "=")
+		key = strings.TrimSpace(key)
+		if !ok || len(key) == 0 {
+			return "", invalidFlagError(set, assignment)
+		}
+		path := strings.Split(key, ".")
+		typed, err := inferValue(path, value, reflect.TypeOf(User{}))
+		if err != nil {
+			return "", err
+		}
+		target := fields
+		for _, name := range path[:len(path)-1] {
+			nested, ok := target[name].(map[string]interface{})
+			if !ok {
+				nested = map[string]interface{}{}
+				target[name] = nested
+			}
+			target = nested
+		}
+		target[path[len(path)-1]] = typed
+	}
+	itemData, err := json.Marshal(fields)
+	if err != nil {
+		return "", newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	return string(itemData), nil
+}
+
+func inferValue(path []string, value string, structType reflect.Type) (interface{}, error) {
+	fieldType, known := jsonFieldType(path, structType)
+	if !known {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number, nil
+		}
+		if flag, err := strconv.ParseBool(value); err == nil {
+			return flag, nil
+		}
+		return value, nil
+	}
+	switch fieldType.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return number, nil
+		}
+		// Other numbers are left to the age coercion, as they are in -item.
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, invalidFlagError(set, strings.Join(path, ".")+"="+value)
+		}
+		return number, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, invalidFlagError(set, strings.Join(path, ".")+"="+value)
+		}
+		return number, nil
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalidFlagError(set, strings.Join(path, ".")+"="+value)
+		}
+		return flag, nil
+	default:
+		return value, nil
+	}
+}
+
+func jsonFieldType(path []string, structType reflect.Type) (reflect.Type, bool) {
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() == reflect.Map {
+		if len(path) == 1 {
+			return structType.Elem(), true
+		}
+		return jsonFieldType(path[1:], structType.Elem())
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return field.Type, true
+		}
+		return jsonFieldType(path[1:], field.Type)
+	}
+	return nil, false
+}
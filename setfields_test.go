@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAddingOperationWithSetFlags(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34,\"address\":{\"city\":\"Kyiv\",\"country\":\"UA\"}}]"
+	args := Arguments{
+		"operation": "add",
+		"set":       "id=1\nemail=test@test.com\nage=34\naddress.city=Kyiv\naddress.country=UA",
+		"fileName":  fileName,
+	}
+
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestSetFlagsInvalidNumber(t *testing.T) {
+	_, err := applySetArgs("", "age=old")
+
+	if code := ErrorCodeOf(err); code != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, code)
+	}
+}
+
+func TestSetFlagsKeepMetadataAsText(t *testing.T) {
+	itemArg, err := applySetArgs("", "id=1\nmetadata.zip=01234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedItem := "{\"id\":\"1\",\"metadata\":{\"zip\":\"01234\"}}"
+	if itemArg != expectedItem {
+		t.Errorf("Expect item to be '%s', but got '%s'", expectedItem, itemArg)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	showDiff = "showDiff"
+	textDiff = "text"
+)
+
+// writeSavedDiff writes what the operation changed in the users file, compared
+// with the users as first loaded, after the output of the operation. It is
+// written as JSON with -format json and as one line per change otherwise.
+func writeSavedDiff(formatArg string, storage *fileStorage, writer io.Writer) {
+	if storage.saved == nil {
+		return
+	}
+	diff, err := compareUsers(storage.shown(storage.before), storage.shown(storage.saved))
+	if err != nil {
+		return
+	}
+	writer.Write([]byte("\n"))
+	if formatArg == jsonFormat {
+		diffData, _ := json.Marshal(diff)
+		writer.Write(diffData)
+		return
+	}
+	var lines []string
+	for _, user := range diff.Added {
+		lines = append(lines, "+ "+user.Id)
+	}
+	for _, user := range diff.Removed {
+		lines = append(lines, "- "+user.Id)
+	}
+	for _, change := range diff.Changed {
+		fromFields, _ := userFields(change.From)
+		toFields, _ := userFields(change.To)
+		for _, name := range change.Fields {
+			lines = append(lines, fmt.Sprintf("~ %s %s: %s -> %s", change.Id, name, diffValue(fromFields[name]), diffValue(toFields[name])))
+		}
+	}
+	writer.Write([]byte(strings.Join(lines, "\n")))
+}
+
+func diffValue(value json.RawMessage) string {
+	if value == nil {
+		return "(none)"
+	}
+	return string(value)
+}
+
+// cloneUsers copies users deeply, so that later changes to maps and pointers
+// do not reach the copy.
+func cloneUsers(users []User) []User {
+	usersData, err := json.Marshal(users)
+	if err != nil {
+		return nil
+	}
+	clone := []User{}
+	if json.Unmarshal(usersData, &clone) != nil {
+		return nil
+	}
+	return clone
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestShowDiffText(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "update", "id": "1", "item": "{\"email\":\"new@test.com\",\"age\":23,\"phone\":\"+380501234567\"}", "showDiff": "true", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "\n~ 1 email: \"test@test.com\" -> \"new@test.com\"\n~ 1 phone: (none) -> \"+380501234567\""
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestShowDiffJSON(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23,\"metadata\":{\"team\":\"a\"}}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "upsert", "onConflict": "merge", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":24,\"metadata\":{\"role\":\"dev\"}}", "showDiff": "true", "format": "json", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"overwritten\"}]\n" +
+		"{\"added\":[],\"removed\":[],\"changed\":[{\"id\":\"1\",\"fields\":[\"age\",\"metadata\"]," +
+		"\"from\":{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23,\"metadata\":{\"team\":\"a\"}}," +
+		"\"to\":{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":24,\"metadata\":{\"role\":\"dev\"}}}]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
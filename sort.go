@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const (
+	sortOp     = "sort"
+	order      = "order"
+	ascending  = "asc"
+	descending = "desc"
+)
+
+// sortUsers reorders the records on disk by a user field. Users with equal
+// values keep their relative order.
+func sortUsers(byArg, orderArg string, storage *fileStorage, writer io.Writer) error {
+	if _, ok := userFieldValue(User{}, byArg); !ok {
+		return invalidFlagError(byField, byArg)
+	}
+	switch orderArg {
+	case "", ascending, descending:
+	default:
+		return invalidFlagError(order, orderArg)
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		a, _ := userFieldValue(users[i], byArg)
+		b, _ := userFieldValue(users[j], byArg)
+		if orderArg == descending {
+			return lessValue(b, a)
+		}
+		return lessValue(a, b)
+	})
+	err = storage.save(users)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "%d users sorted by %s", len(users), byArg)
+	return nil
+}
+
+// lessValue orders numbers by value and puts them before other values, which
+// are ordered as case folded text.
+func lessValue(a, b string) bool {
+	aNumber, aErr := strconv.ParseFloat(a, 64)
+	bNumber, bErr := strconv.ParseFloat(b, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return aNumber < bNumber
+	case aErr == nil || bErr == nil:
+		return aErr == nil
+	}
+	aFolded, bFolded := foldText(a), foldText(b)
+	if aFolded != bFolded {
+		return aFolded < bFolded
+	}
+	return a < b
+}
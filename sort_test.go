@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSortOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":9},{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":17}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "sort", "by": "age", "order": "desc", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"2\",\"email\":\"test2@test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"test3@test.com\",\"age\":17},{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":9}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestSortOperationWrongField(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	err := Perform(Arguments{"operation": "sort", "by": "name", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
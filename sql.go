@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	sqlFormat       = "sql"
+	table           = "table"
+	dialect         = "dialect"
+	defaultTable    = "users"
+	sqliteDialect   = "sqlite"
+	postgresDialect = "postgres"
+	mysqlDialect    = "mysql"
+)
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var sqlColumns = []string{"id", "email", "email_ascii", "age", "phone", "street", "city", "country", "metadata", "dob", "status"}
+
+type sqlWriter struct {
+	dialect string
+	table   string
+}
+
+func exportSQL(args Arguments, storage *fileStorage, writer io.Writer) error {
+	if len(args[chunkSize]) > 0 {
+		return invalidFlagError(chunkSize, args[chunkSize])
+	}
+	sql := sqlWriter{dialect: args[dialect], table: args[table]}
+	switch sql.dialect {
+	case "":
+		sql.dialect = sqliteDialect
+	case sqliteDialect, postgresDialect, mysqlDialect:
+	default:
+		return invalidFlagError(dialect, sql.dialect)
+	}
+	if len(sql.table) == 0 {
+		sql.table = defaultTable
+	}
+	if !sqlIdentifier.MatchString(sql.table) {
+		return invalidFlagError(table, sql.table)
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	var script bytes.Buffer
+	sql.writeCreateTable(&script)
+	for _, user := range users {
+		err = sql.writeInsert(&script, user)
+		if err != nil {
+			return err
+		}
+	}
+	outputArg := args[output]
+	if len(outputArg) == 0 {
+		writer.Write(script.Bytes())
+		return nil
+	}
+	err = os.WriteFile(osPath(outputArg), script.Bytes(), 0644)
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing SQL export: %w", err),
+			map[string]string{output: outputArg})
+	}
+	return nil
+}
+
+func (w sqlWriter) quoteIdentifier(name string) string {
+	if w.dialect == mysqlDialect {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+func (w sqlWriter) quoteString(value string) string {
+	if w.dialect == mysqlDialect {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (w sqlWriter) optionalString(value string) string {
+	if len(value) == 0 {
+		return "NULL"
+	}
+	return w.quoteString(value)
+}
+
+func (w sqlWriter) writeCreateTable(script io.Writer) {
+	keyType := "TEXT"
+	if w.dialect == mysqlDialect {
+		keyType = "VARCHAR(255)"
+	}
+	columns := []string{
+		w.quoteIdentifier("id") + " " + keyType + " PRIMARY KEY",
+		w.quoteIdentifier("email") + " TEXT NOT NULL",
+		w.quoteIdentifier("email_ascii") + " TEXT",
+		w.quoteIdentifier("age") + " INTEGER NOT NULL",
+		w.quoteIdentifier("phone") + " TEXT",
+		w.quoteIdentifier("street") + " TEXT",
+		w.quoteIdentifier("city") + " TEXT",
+		w.quoteIdentifier("country") + " TEXT",
+		w.quoteIdentifier("metadata") + " TEXT",
+		w.quoteIdentifier("dob") + " TEXT",
+		w.quoteIdentifier("status") + " TEXT",
+	}
+	fmt.Fprintf(script, "CREATE TABLE %s (\n  %s\n);\n", w.quoteIdentifier(w.table), strings.Join(columns, ",\n  "))
+}
+
+func (w sqlWriter) writeInsert(script io.Writer, user User) error {
+	address := Address{}
+	if user.Address != nil {
+		address = *user.Address
+	}
+	metadata := "NULL"
+	if len(user.Metadata) > 0 {
+		metadataData, err := json.Marshal(user.Metadata)
+		if err != nil {
+			return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+		}
+		metadata = w.quoteString(string(metadataData))
+	}
+	values := []string{
+		w.quoteString(user.Id),
+		w.quoteString(user.Email),
+		w.optionalString(user.EmailAscii),
+		strconv.FormatUint(uint64(user.Age), 10),
+		w.optionalString(user.Phone),
+		w.optionalString(address.Street),
+		w.optionalString(address.City),
+		w.optionalString(address.Country),
+		metadata,
+		w.optionalString(user.Dob),
+		w.optionalString(user.Status),
+	}
+	columns := make([]string, len(sqlColumns))
+	for i, column := range sqlColumns {
+		columns[i] = w.quoteIdentifier(column)
+	}
+	fmt.Fprintf(script, "INSERT INTO %s (%s) VALUES (%s);\n", w.quoteIdentifier(w.table),
+		strings.Join(columns, ", "), strings.Join(values, ", "))
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestExportSQL(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"o'neil@test.com\",\"age\":34,\"address\":{\"city\":\"Kyiv\",\"country\":\"UA\"}}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "export", "format": "sql", "dialect": "mysql", "table": "people", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "CREATE TABLE `people` (\n" +
+		"  `id` VARCHAR(255) PRIMARY KEY,\n  `email` TEXT NOT NULL,\n  `email_ascii` TEXT,\n  `age` INTEGER NOT NULL,\n" +
+		"  `phone` TEXT,\n  `street` TEXT,\n  `city` TEXT,\n  `country` TEXT,\n  `metadata` TEXT,\n  `dob` TEXT,\n  `status` TEXT\n);\n" +
+		"INSERT INTO `people` (`id`, `email`, `email_ascii`, `age`, `phone`, `street`, `city`, `country`, `metadata`, `dob`, `status`) " +
+		"VALUES ('1', 'o''neil@test.com', NULL, 34, NULL, NULL, 'Kyiv', 'UA', NULL, NULL, NULL);\n"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestExportSQLWrongTable(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	args := Arguments{"operation": "export", "format": "sql", "table": "users; drop", "fileName": fileName}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	importSqlOp = "importSql"
+	input       = "input"
+)
+
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlQuotedIdentifier
+	sqlString
+	sqlPunct
+)
+
+type sqlToken struct {
+	kind  sqlTokenKind
+	value string
+}
+
+type sqlRow map[string]*string
+
+// importSqlDump adds the users found in the INSERT statements of a SQL dump.
+// Other statements are skipped, the columns follow the sql export format.
+func importSqlDump(inputArg, onConflictArg, idTypeArg, countryArg string, pattern *regexp.Regexp, strict bool,
+	storage *fileStorage, writer io.Writer) error {
+	if len(inputArg) == 0 {
+		return missingFlagError(input)
+	}
+	dump, err := os.ReadFile(osPath(inputArg))
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while reading SQL dump: %w", err),
+			map[string]string{input: inputArg})
+	}
+	tokens, err := tokenizeSql(string(dump))
+	if err != nil {
+		return err
+	}
+	rows, err := parseSqlInserts(tokens)
+	if err != nil {
+		return err
+	}
+	if len(onConflictArg) == 0 {
+		onConflictArg = skipOnConflict
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	changed := false
+	results := make([]recordResult, 0, len(rows))
+	for _, row := range rows {
+		pendingUser, err := userFromSqlRow(row, strict)
+		if err != nil {
+			return err
+		}
+		err = prepareUser(&pendingUser, users, idTypeArg, countryArg, pattern)
+		if err != nil {
+			return err
+		}
+		var result string
+		users, result, err = applyConflictPolicy(users, pendingUser, onConflictArg)
+		if err != nil {
+			return err
+		}
+		changed = changed || result != skippedResult
+		results = append(results, recordResult{Id: pendingUser.Id, Result: result})
+	}
+	if changed {
+		err = storage.save(users)
+		if err != nil {
+			return err
+		}
+	}
+	resultsData, err := json.Marshal(results)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(resultsData)
+	return nil
+}
+
+func sqlSyntaxError(message string) error {
+	return newOperationError(CodeInvalidData, fmt.Errorf("Error while parsing SQL dump: %s", message), nil)
+}
+
+func tokenizeSql(dump string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(dump)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\'' || r == '"' || r == '`':
+			var value strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && r == '\'' && i+1 < len(runes) {
+					i++
+					value.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						value.WriteRune(r)
+						i++
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, sqlSyntaxError("unterminated quoted value")
+			}
+			kind := sqlQuotedIdentifier
+			if r == '\'' {
+				kind = sqlString
+			}
+			tokens = append(tokens, sqlToken{kind: kind, value: value.String()})
+		case strings.ContainsRune("(),;", r):
+			tokens = append(tokens, sqlToken{kind: sqlPunct, value: string(r)})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),;'\"`", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlWord, value: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+func isSqlKeyword(token sqlToken, keyword string) bool {
+	return token.kind == sqlWord && strings.EqualFold(token.value, keyword)
+}
+
+func isSqlPunct(tokens []sqlToken, i int, punct string) bool {
+	return i < len(tokens) && tokens[i].kind == sqlPunct && tokens[i].value == punct
+}
+
+func parseSqlInserts(tokens []sqlToken) ([]sqlRow, error) {
+	var rows []sqlRow
+	for i := 0; i < len(tokens); {
+		if !isSqlKeyword(tokens[i], "insert") {
+			for i < len(tokens) && !isSqlPunct(tokens, i, ";") {
+				i++
+			}
+			i++
+			continue
+		}
+		i++
+		if i >= len(tokens) || !isSqlKeyword(tokens[i], "into") {
+			return nil, sqlSyntaxError("expected INTO after INSERT")
+		}
+		i += 2
+		var columns []string
+		if isSqlPunct(tokens, i, "(") {
+			for i++; i < len(tokens) && !isSqlPunct(tokens, i, ")"); i++ {
+				if tokens[i].kind == sqlWord || tokens[i].kind == sqlQuotedIdentifier {
+					columns = append(columns, strings.ToLower(tokens[i].value))
+				}
+			}
+			i++
+		}
+		if len(columns) == 0 {
+			columns = sqlColumns
+		}
+		if i >= len(tokens) || !isSqlKeyword(tokens[i], "values") {
+			return nil, sqlSyntaxError("expected VALUES in INSERT")
+		}
+		for i++; isSqlPunct(tokens, i, "("); {
+			row := sqlRow{}
+			position := 0
+			for i++; i < len(tokens) && !isSqlPunct(tokens, i, ")"); i++ {
+				if isSqlPunct(tokens, i, ",") {
+					continue
+				}
+				if position >= len(columns) {
+					return nil, sqlSyntaxError("more values than columns in INSERT")
+				}
+				if !isSqlKeyword(tokens[i], "null") {
+					value := tokens[i].value
+					row[columns[position]] = &value
+				}
+				position++
+			}
+			if i >= len(tokens) {
+				return nil, sqlSyntaxError("unterminated VALUES list")
+			}
+			rows = append(rows, row)
+			i++
+			if isSqlPunct(tokens, i, ",") {
+				i++
+			}
+		}
+	}
+	return rows, nil
+}
+
+func userFromSqlRow(row sqlRow, strict bool) (User, error) {
+	text := func(column string) string {
+		if value := row[column]; value != nil {
+			return *value
+		}
+		return ""
+	}
+	user := User{
+		Id:         text("id"),
+		Email:      text("email"),
+		EmailAscii: text("email_ascii"),
+		Phone:      text("phone"),
+		Dob:        text("dob"),
+		Status:     text("status"),
+	}
+	if len(user.Id) == 0 {
+		return user, sqlSyntaxError("row without id")
+	}
+	if value := text("age"); len(value) > 0 {
+		age, err := strconv.ParseUint(value, 10, 0)
+		user.Age = uint(age)
+		if err != nil && !strict {
+			user.Age, err = lenientAge(value)
+		}
+		if err != nil {
+			return user, sqlSyntaxError(fmt.Sprintf("invalid age %s for id %s", value, user.Id))
+		}
+	}
+	if street, city, country := text("street"), text("city"), text("country"); len(street)+len(city)+len(country) > 0 {
+		user.Address = &Address{Street: street, City: city, Country: country}
+	}
+	if value := text("metadata"); len(value) > 0 {
+		err := json.Unmarshal([]byte(value), &user.Metadata)
+		if err != nil {
+			return user, sqlSyntaxError(fmt.Sprintf("invalid metadata for id %s", user.Id))
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestImportSqlDump(t *testing.T) {
+	var buffer bytes.Buffer
+	dumpFileName := "dump.sql"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	dump := "-- accounts\nCREATE TABLE accounts (id TEXT, email TEXT, age INTEGER);\n" +
+		"INSERT INTO accounts (id, email, age, city, country) VALUES ('1', 'old@test.com', 40, NULL, NULL), ('2', 'o''neil@test.com', 31, 'Kyiv', 'UA');\n"
+	err = ioutil.WriteFile(dumpFileName, []byte(dump), filePermission)
+	defer os.Remove(dumpFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "importSql", "input": dumpFileName, "fileName": fileName}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"result\":\"skipped\"},{\"id\":\"2\",\"result\":\"added\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"o'neil@test.com\",\"age\":31,\"address\":{\"city\":\"Kyiv\",\"country\":\"UA\"}}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestImportSqlDumpChecksIdPattern(t *testing.T) {
+	var buffer bytes.Buffer
+	dumpFileName := "dump.sql"
+	defer os.Remove(fileName)
+
+	dump := "INSERT INTO users (id, email, age) VALUES ('EMP-000001', 'test@test.com', 34), ('2', 'test2@test.com', 31);\n"
+	err := ioutil.WriteFile(dumpFileName, []byte(dump), filePermission)
+	defer os.Remove(dumpFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	args := Arguments{"operation": "importSql", "input": dumpFileName, "idPattern": "EMP-\\d{6}", "fileName": fileName}
+	err = Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodeValidation {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeValidation, ErrorCodeOf(err))
+	}
+	bytes, _ := ioutil.ReadFile(fileName)
+	if strings.Contains(string(bytes), "test@test.com") {
+		t.Errorf("Expect no users to be saved, but got %s", bytes)
+	}
+}
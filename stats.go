@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const statsOp = "stats"
+
+// usersStats leaves the age aggregates null for a file without users.
+type usersStats struct {
+	Count        int      `json:"count"`
+	MinAge       *uint    `json:"minAge"`
+	MaxAge       *uint    `json:"maxAge"`
+	AverageAge   *float64 `json:"averageAge"`
+	EmailDomains int      `json:"emailDomains"`
+	FileSize     int64    `json:"fileSize"`
+}
+
+func usersFileStats(storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(osPath(storage.fileName))
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf(openFileErrorMsg, err),
+			map[string]string{userFileName: storage.fileName})
+	}
+	stats := usersStats{Count: len(users), FileSize: info.Size()}
+	domains := map[string]bool{}
+	var ageSum float64
+	for i, user := range users {
+		if i == 0 || user.Age < *stats.MinAge {
+			stats.MinAge = &users[i].Age
+		}
+		if i == 0 || user.Age > *stats.MaxAge {
+			stats.MaxAge = &users[i].Age
+		}
+		ageSum += float64(user.Age)
+		if domain := emailDomain(user.Email); len(domain) > 0 {
+			domains[domain] = true
+		}
+	}
+	if len(users) > 0 {
+		averageAge := ageSum / float64(len(users))
+		stats.AverageAge = &averageAge
+	}
+	stats.EmailDomains = len(domains)
+	statsData, err := json.Marshal(stats)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(statsData)
+	return nil
+}
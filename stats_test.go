@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestStatsOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	fileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":20},{\"id\":\"2\",\"email\":\"test2@Test.com\",\"age\":31},{\"id\":\"3\",\"email\":\"test3@example.com\",\"age\":27}]"
+	err := ioutil.WriteFile(fileName, []byte(fileContent), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "stats", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "{\"count\":3,\"minAge\":20,\"maxAge\":31,\"averageAge\":26,\"emailDomains\":2,\"fileSize\":138}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestStatsOperationEmptyFile(t *testing.T) {
+	var buffer bytes.Buffer
+	defer os.Remove(fileName)
+
+	err := Perform(Arguments{"operation": "stats", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "{\"count\":0,\"minAge\":null,\"maxAge\":null,\"averageAge\":null,\"emailDomains\":0,\"fileSize\":0}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	retries             = "retries"
+	retryBackoff        = "retryBackoff"
+	retryJitter         = "retryJitter"
+	timeout             = "timeout"
+	defaultRetries      = 1
+	defaultRetryBackoff = 100 * time.Millisecond
+)
+
+var after = time.After
+
+// States of a write racing the operation deadline, see run.
+const (
+	writePending int32 = iota
+	writeCommitted
+	writeAbandoned
+)
+
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+	jitter   time.Duration
+}
+
+type fileStorage struct {
+	ctx      context.Context
+	fileName string
+	retry    retryPolicy
+	emails   *emailCipher
+	// encrypted is set when users were loaded without -emailKey from a
+	// file holding encrypted emails.
+	encrypted bool
+	mirror    string
+	warnings  io.Writer
+	// diagnostics receives messages such as a missing id, kept apart from
+	// the data the operation writes.
+	diagnostics io.Writer
+	strict      bool
+	fallback    string
+	degraded    bool
+	layout      fileLayout
+	// writes is set by writable for the operations that save. -hideNotes
+	// only strips notes from the users of the others, so that hidden notes
+	// can not be lost on write.
+	writes    bool
+	hideNotes bool
+	// masked holds the [visibility] policies of -profile. They are applied
+	// on load for read-only operations and to the output of writing ones.
+	masked fieldPolicies
+	// before and saved keep the users as first loaded and as last saved
+	// for -showDiff.
+	showDiff bool
+	before   []User
+	saved    []User
+	preserve bool
+	// keep resolves duplicate ids on load unless rawIds is set.
+	keep   string
+	rawIds bool
+	raw    *rawUsers
+}
+
+func newFileStorage(ctx context.Context, args Arguments) (*fileStorage, error) {
+	retry, err := parseRetryPolicy(args)
+	if err != nil {
+		return nil, err
+	}
+	err = validateMirrorArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	err = validateFallbackArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	err = validateKeepArg(args)
+	if err != nil {
+		return nil, err
+	}
+	masked, err := loadVisibility(args)
+	if err != nil {
+		return nil, err
+	}
+	storage := &fileStorage{
+		ctx:         ctx,
+		fileName:    args[userFileName],
+		retry:       retry,
+		mirror:      args[mirror],
+		warnings:    os.Stderr,
+		diagnostics: os.Stderr,
+		strict:      args[mirrorMode] == strictMirror,
+		fallback:    args[fallback],
+		hideNotes:   args[hideNotes] == "true",
+		masked:      masked,
+		showDiff:    args[showDiff] == "true",
+		preserve:    args[preserveFormat] == "true",
+		keep:        args[keep],
+		rawIds:      duplicateOps[args[operation]],
+	}
+	if key := args[emailKey]; len(key) > 0 {
+		storage.emails, err = newEmailCipher(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return storage, nil
+}
+
+func operationContext(args Arguments) (context.Context, context.CancelFunc, error) {
+	value := args[timeout]
+	if len(value) == 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, cancel, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return nil, nil, invalidFlagError(timeout, value)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	return ctx, cancel, nil
+}
+
+func parseRetryPolicy(args Arguments) (retryPolicy, error) {
+	policy := retryPolicy{attempts: defaultRetries, backoff: defaultRetryBackoff}
+	var err error
+	if value := args[retries]; len(value) > 0 {
+		policy.attempts, err = strconv.Atoi(value)
+		if err != nil || policy.attempts < 1 {
+			return policy, invalidFlagError(retries, value)
+		}
+	}
+	if value := args[retryBackoff]; len(value) > 0 {
+		policy.backoff, err = time.ParseDuration(value)
+		if err != nil || policy.backoff < 0 {
+			return policy, invalidFlagError(retryBackoff, value)
+		}
+	}
+	if value := args[retryJitter]; len(value) > 0 {
+		policy.jitter, err = time.ParseDuration(value)
+		if err != nil || policy.jitter < 0 {
+			return policy, invalidFlagError(retryJitter, value)
+		}
+	}
+	return policy, nil
+}
+
+func (s *fileStorage) load() ([]User, error) {
+	var users []User
+	err := s.withRetry(func() error {
+		var loaded []User
+		var layout fileLayout
+		err := s.run(func(func() error) error {
+			var err error
+			loaded, layout, err = loadUsersFromFile(s.fileName)
+			return err
+		})
+		if err == nil {
+			users, s.layout = loaded, layout
+		}
+		return err
+	})
+	if err != nil {
+		users, err = s.loadFallback(err)
+	}
+	if err == nil && s.emails != nil {
+		err = s.emails.decryptUsers(users)
+	}
+	if err == nil && s.emails == nil {
+		s.encrypted = s.encrypted || hasEncryptedEmails(users)
+	}
+	if err == nil && s.preserve && len(bytes.TrimSpace(s.layout.source)) > 0 {
+		s.raw, err = indexRawUsers(s.layout, users)
+		if err != nil {
+			err = newOperationError(CodeInvalidData, fmt.Errorf("Error while indexing users file for -%s: %w", preserveFormat, err),
+				map[string]string{userFileName: s.fileName})
+		}
+	}
+	if err == nil && !s.rawIds {
+		users, err = resolveDuplicateIds(users, s.keep, s.fileName)
+	}
+	if err == nil && s.hideNotes && !s.writes {
+		stripNotes(users)
+	}
+	if err == nil && s.masked != nil && !s.writes {
+		s.masked.apply(users)
+	}
+	if err == nil && s.showDiff && s.before == nil {
+		s.before = cloneUsers(users)
+	}
+	return users, err
+}
+
+func (s *fileStorage) save(users []User) error {
+	if s.degraded {
+		return degradedError(s)
+	}
+	if !s.writes {
+		return newOperationError(CodeInternal, fmt.Errorf("Users file %s is read-only for this operation", s.fileName),
+			map[string]string{userFileName: s.fileName})
+	}
+	if s.encrypted {
+		return newOperationError(CodeValidation,
+			fmt.Errorf("Users file %s holds encrypted emails, -%s has to be specified to change it", s.fileName, emailKey),
+			map[string]string{"flag": emailKey, userFileName: s.fileName})
+	}
+	if err := checkOwnership(s.fileName); err != nil {
+		return err
+	}
+	plain := users
+	if s.emails != nil {
+		var err error
+		users, err = s.emails.encryptUsers(users)
+		if err != nil {
+			return err
+		}
+	}
+	err := s.withRetry(func() error {
+		return s.run(func(commit func() error) error {
+			if s.raw != nil && s.raw.layout.version == s.layout.version && s.raw.layout.indented == s.layout.indented {
+				usersData, err := s.raw.splice(plain, users)
+				if err != nil {
+					return err
+				}
+				return writeUsersData(usersData, s.fileName, commit)
+			}
+			return saveUsersToFile(users, s.layout, s.fileName, commit)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if s.showDiff {
+		s.saved = cloneUsers(plain)
+	}
+	return s.saveMirror(users)
+}
+
+// writable declares, where an operation is dispatched, that it saves users.
+// Storage is read-only otherwise.
+func (s *fileStorage) writable() *fileStorage {
+	s.writes = true
+	return s
+}
+
+// run does action unless ctx ends first. A write calls commit right before
+// it replaces a file: once commit succeeds run waits for the write to finish
+// instead of timing out, and once run has timed out commit fails, so a
+// TIMEOUT always means the file was left untouched.
+func (s *fileStorage) run(action func(commit func() error) error) error {
+	if err := s.ctx.Err(); err != nil {
+		return timeoutError(err)
+	}
+	state := writePending
+	commit := func() error {
+		if err := s.ctx.Err(); err != nil {
+			return timeoutError(err)
+		}
+		if !atomic.CompareAndSwapInt32(&state, writePending, writeCommitted) {
+			return timeoutError(s.ctx.Err())
+		}
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- action(commit)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-s.ctx.Done():
+		if atomic.CompareAndSwapInt32(&state, writePending, writeAbandoned) {
+			return timeoutError(s.ctx.Err())
+		}
+		return <-done
+	}
+}
+
+func timeoutError(err error) error {
+	return newOperationError(CodeTimeout, fmt.Errorf("Storage operation was interrupted: %w", err), nil)
+}
+
+func (s *fileStorage) withRetry(action func() error) error {
+	var err error
+	for attempt := 1; attempt <= s.retry.attempts; attempt++ {
+		err = action()
+		if err == nil || !isTransient(err) || attempt == s.retry.attempts {
+			return err
+		}
+		delay := s.retry.backoff << (attempt - 1)
+		if s.retry.jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(s.retry.jitter)))
+		}
+		select {
+		case <-after(delay):
+		case <-s.ctx.Done():
+			return timeoutError(s.ctx.Err())
+		}
+	}
+	return err
+}
+
+func isTransient(err error) bool {
+	if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.ETIMEDOUT) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func invalidFlagError(flagName, value string) error {
+	return newOperationError(CodeValidation, fmt.Errorf("Invalid value %s for -%s flag", value, flagName),
+		map[string]string{"flag": flagName, "value": value})
+}
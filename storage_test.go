@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStorageRetriesTransientErrors(t *testing.T) {
+	var delays []time.Duration
+	after = func(d time.Duration) <-chan time.Time {
+		delays = append(delays, d)
+		elapsed := make(chan time.Time, 1)
+		elapsed <- time.Now()
+		return elapsed
+	}
+	defer func() { after = time.After }()
+
+	storage, err := newFileStorage(context.Background(), Arguments{"fileName": fileName, "retries": "3", "retryBackoff": "10ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	err = storage.withRetry(func() error {
+		calls++
+		if calls < 3 {
+			return newOperationError(CodeStorageIO, syscall.EBUSY, nil)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+	if calls != 3 {
+		t.Errorf("Expect 3 attempts, but got %d", calls)
+	}
+	if len(delays) != 2 || delays[0] != 10*time.Millisecond || delays[1] != 20*time.Millisecond {
+		t.Errorf("Expect delays to be [10ms 20ms], but got %v", delays)
+	}
+}
+
+func TestStorageDoesNotRetryPermanentErrors(t *testing.T) {
+	storage, err := newFileStorage(context.Background(), Arguments{"fileName": fileName, "retries": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	err = storage.withRetry(func() error {
+		calls++
+		return errors.New("permanent")
+	})
+
+	if err == nil {
+		t.Error("Expect error to be returned")
+	}
+	if calls != 1 {
+		t.Errorf("Expect 1 attempt, but got %d", calls)
+	}
+}
+
+func TestStorageTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	storage, err := newFileStorage(ctx, Arguments{"fileName": fileName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.run(func(func() error) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	if code := ErrorCodeOf(err); code != CodeTimeout {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeTimeout, code)
+	}
+}
+
+func TestStorageTimeoutAbandonsUncommittedWrite(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	storage, err := newFileStorage(ctx, Arguments{"fileName": fileName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	committed := make(chan error, 1)
+	err = storage.run(func(commit func() error) error {
+		time.Sleep(50 * time.Millisecond)
+		committed <- commit()
+		return nil
+	})
+
+	if code := ErrorCodeOf(err); code != CodeTimeout {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeTimeout, code)
+	}
+	if code := ErrorCodeOf(<-committed); code != CodeTimeout {
+		t.Errorf("Expect the late commit to fail with '%s', but got '%s'", CodeTimeout, code)
+	}
+}
+
+func TestStorageTimeoutWaitsForCommittedWrite(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	storage, err := newFileStorage(ctx, Arguments{"fileName": fileName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.run(func(commit func() error) error {
+		if err := commit(); err != nil {
+			return err
+		}
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expect the committed write to be waited for, but got %v", err)
+	}
+}
+
+func TestStorageRetryBackoffStopsOnTimeout(t *testing.T) {
+	after = func(time.Duration) <-chan time.Time { return nil }
+	defer func() { after = time.After }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	storage, err := newFileStorage(ctx, Arguments{"fileName": fileName, "retries": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.withRetry(func() error {
+		return newOperationError(CodeStorageIO, syscall.EBUSY, nil)
+	})
+
+	if code := ErrorCodeOf(err); code != CodeTimeout {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeTimeout, code)
+	}
+}
+
+func TestWriteUsersDataReplacesFile(t *testing.T) {
+	err := ioutil.WriteFile(fileName, []byte("[]"), 0600)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = writeUsersData([]byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), fileName, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expect file mode to be '%v', but got '%v'", os.FileMode(0600), info.Mode().Perm())
+	}
+	shadows, _ := filepath.Glob("." + fileName + ".*")
+	if len(shadows) > 0 {
+		t.Errorf("Expect no shadow copy to be left, but got %v", shadows)
+	}
+}
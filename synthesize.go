@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+)
+
+const (
+	synthesizeOp           = "synthesize"
+	like                   = "like"
+	userCount              = "count"
+	defaultSynthesizeCount = 1000
+)
+
+// synthesizeUsers fills the empty -fileName with -count fake users whose ages,
+// email domains and countries follow the frequencies of the -like file. No
+// id, email local part or other value of a real user is copied.
+func synthesizeUsers(args Arguments, storage *fileStorage, writer io.Writer) error {
+	likeArg := args[like]
+	if len(likeArg) == 0 {
+		return missingFlagError(like)
+	}
+	if samePath(likeArg, storage.fileName) {
+		return invalidFlagError(like, likeArg)
+	}
+	size, err := positiveIntArg(args, userCount, defaultSynthesizeCount)
+	if err != nil {
+		return err
+	}
+	seedValue := now().UnixNano()
+	if len(args[seed]) > 0 {
+		seedValue, err = strconv.ParseInt(args[seed], 10, 64)
+		if err != nil {
+			return invalidFlagError(seed, args[seed])
+		}
+	}
+	likeData, err := os.ReadFile(osPath(likeArg))
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf(openFileErrorMsg, err),
+			map[string]string{like: likeArg})
+	}
+	realUsers, _, err := decodeUsers(likeData)
+	if err != nil {
+		return withDetail(err, like, likeArg)
+	}
+	if storage.emails != nil {
+		err = storage.emails.decryptUsers(realUsers)
+		if err != nil {
+			return err
+		}
+	}
+	if len(realUsers) == 0 {
+		return newOperationError(CodeValidation, fmt.Errorf("Users file %s has no users to learn from", likeArg),
+			map[string]string{like: likeArg})
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return newOperationError(CodeConflict, fmt.Errorf("Users file %s is not empty", storage.fileName),
+			map[string]string{userFileName: storage.fileName})
+	}
+
+	var ages []uint
+	var domains, countries []string
+	for _, user := range realUsers {
+		ages = append(ages, user.Age)
+		if domain := emailDomain(user.Email); len(domain) > 0 {
+			domains = append(domains, domain)
+		}
+		if user.Address != nil && len(user.Address.Country) > 0 {
+			countries = append(countries, user.Address.Country)
+		}
+	}
+	if len(domains) == 0 {
+		domains = []string{"example.com"}
+	}
+	random := rand.New(rand.NewSource(seedValue))
+	users = make([]User, 0, size)
+	for i := 1; i <= size; i++ {
+		userId := strconv.Itoa(i)
+		user := User{
+			Id:    userId,
+			Email: "user" + userId + "@" + domains[random.Intn(len(domains))],
+			Age:   ages[random.Intn(len(ages))],
+		}
+		// Only as many users get an address as in the real file.
+		if random.Intn(len(realUsers)) < len(countries) {
+			user.Address = &Address{Country: countries[random.Intn(len(countries))]}
+		}
+		users = append(users, user)
+	}
+	err = storage.save(users)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "%d users synthesized like %s", len(users), likeArg)
+	return nil
+}
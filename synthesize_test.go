@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSynthesizeOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	likeFileName := "like.json"
+
+	err := ioutil.WriteFile(likeFileName, []byte("[{\"id\":\"a\",\"email\":\"real@corp.com\",\"age\":30},{\"id\":\"b\",\"email\":\"other@corp.com\",\"age\":40}]"), filePermission)
+	defer os.Remove(likeFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(fileName)
+
+	err = Perform(Arguments{"operation": "synthesize", "like": likeFileName, "count": "20", "seed": "7", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "20 users synthesized like like.json"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	var users []User
+	err = json.Unmarshal(bytes, &users)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(users) != 20 {
+		t.Errorf("Expect 20 users, but got %d", len(users))
+	}
+	for _, user := range users {
+		if !strings.HasSuffix(user.Email, "@corp.com") || strings.HasPrefix(user.Email, "real") || (user.Age != 30 && user.Age != 40) {
+			t.Errorf("Expect a fake user like the real ones, but got %+v", user)
+		}
+	}
+}
+
+func TestSynthesizeOperationNotEmpty(t *testing.T) {
+	var buffer bytes.Buffer
+	likeFileName := "like.json"
+
+	err := ioutil.WriteFile(likeFileName, []byte("[{\"id\":\"a\",\"email\":\"real@corp.com\",\"age\":30}]"), filePermission)
+	defer os.Remove(likeFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "synthesize", "like": likeFileName, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeConflict {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeConflict, ErrorCodeOf(err))
+	}
+}
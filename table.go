@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const tableFormat = "table"
+
+// writeUsersTable writes users as aligned ID, EMAIL and AGE columns, followed
+// by a column per computed field.
+func writeUsersTable(users []User, fields []computedField, writer io.Writer) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	header := []string{"ID", "EMAIL", "AGE"}
+	for _, field := range fields {
+		header = append(header, strings.ToUpper(field.name))
+	}
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+	for _, user := range users {
+		row := []string{user.Id, user.Email, fmt.Sprint(user.Age)}
+		for _, field := range fields {
+			row = append(row, fmt.Sprint(field.eval(user)))
+		}
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	err := table.Flush()
+	if err != nil {
+		return newOperationError(CodeStorageIO, fmt.Errorf("Error while writing table: %w", err), nil)
+	}
+	return nil
+}
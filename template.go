@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	texttemplate "text/template"
+)
+
+const (
+	templateFormat = "template"
+	templateAction = "{{"
+	// templateSource is not a flag, it carries the template resolved for
+	// -format template to the users encoders.
+	templateSource = "templateSource"
+)
+
+// resolveTemplateFormat reads the template of -format template. It is
+// -template itself when it holds a template action, with \t and \n read as
+// tab and newline like docker does, and the template file it names otherwise.
+// -format template:<name> reads the template from the config catalog and is
+// turned into -format template.
+func resolveTemplateFormat(args Arguments) (Arguments, error) {
+	var source string
+	switch {
+	case strings.HasPrefix(args[format], catalogFormatPrefix):
+		var err error
+		source, err = catalogTemplate(args)
+		if err != nil {
+			return nil, err
+		}
+	case args[format] == templateFormat:
+		var err error
+		source, err = readTemplateArg(args[templateFile])
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return args, nil
+	}
+	templateArgs := make(Arguments, len(args))
+	for key, value := range args {
+		templateArgs[key] = value
+	}
+	templateArgs[format] = templateFormat
+	templateArgs[templateSource] = source
+	return templateArgs, nil
+}
+
+func readTemplateArg(templateArg string) (string, error) {
+	if len(templateArg) == 0 {
+		return "", missingFlagError(templateFile)
+	}
+	source := strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(templateArg)
+	if !strings.Contains(templateArg, templateAction) {
+		data, err := os.ReadFile(osPath(templateArg))
+		if err != nil {
+			return "", newOperationError(CodeStorageIO, fmt.Errorf("Error while reading template: %w", err),
+				map[string]string{templateFile: templateArg})
+		}
+		source = string(data)
+	}
+	return source, nil
+}
+
+// writeUsersTemplate renders the template once per user, one user per line.
+func writeUsersTemplate(users []User, fields []computedField, source string, writer io.Writer) error {
+	document, err := texttemplate.New(templateFormat).Parse(source)
+	if err != nil {
+		return templateParseError(templateFormat, err)
+	}
+	var rendered bytes.Buffer
+	for _, user := range users {
+		err = renderDocument(document, user, &rendered)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(source, "\n") {
+			rendered.WriteString("\n")
+		}
+	}
+	writer.Write(rendered.Bytes())
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	tenant               = "tenant"
+	tenantPattern        = "tenantPattern"
+	tenantsOp            = "tenants"
+	tenantsSection       = "tenants"
+	tenantPlaceholder    = "%s"
+	defaultTenantPattern = "tenants/%s.json"
+)
+
+var tenantName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// tenantPathFlags name the files an operation reads or writes besides
+// -fileName. -configFile is left out, it is shared by all tenants.
+var tenantPathFlags = []string{mirror, fallback, output, idsFile, mappingFile, like, input, from, to, templateFile, notifyTemplate}
+
+// tenantFilePattern returns the pattern mapping a tenant to its users file:
+// -tenantPattern, then pattern in the [tenants] config section, then the
+// default.
+func tenantFilePattern(args Arguments) (string, error) {
+	pattern := args[tenantPattern]
+	if len(pattern) == 0 && len(args[configFile]) > 0 {
+		settings, err := loadConfig(args[configFile])
+		if err != nil {
+			return "", err
+		}
+		pattern = settings[tenantsSection]["pattern"]
+	}
+	if len(pattern) == 0 {
+		pattern = defaultTenantPattern
+	}
+	if strings.Count(pattern, tenantPlaceholder) != 1 || strings.Count(pattern, "%") != 1 {
+		return "", invalidFlagError(tenantPattern, pattern)
+	}
+	return pattern, nil
+}
+
+// resolveTenant points -fileName at the users file of -tenant. Naming another
+// users file, any other path outside the files of the tenant, or running
+// across many files, is refused.
+func resolveTenant(args Arguments) (Arguments, error) {
+	tenantArg := args[tenant]
+	if len(tenantArg) == 0 {
+		return args, nil
+	}
+	if !tenantName.MatchString(tenantArg) {
+		return nil, invalidFlagError(tenant, tenantArg)
+	}
+	pattern, err := tenantFilePattern(args)
+	if err != nil {
+		return nil, err
+	}
+	tenantFile := fmt.Sprintf(pattern, tenantArg)
+	if len(args[files]) > 0 {
+		return nil, crossTenantError(tenantArg, files, args[files])
+	}
+	ownDir := strings.Contains(filepath.Dir(pattern), tenantPlaceholder)
+	for _, pathFlag := range tenantPathFlags {
+		value := args[pathFlag]
+		if len(value) == 0 || pathFlag == to && args[operation] != copyOp ||
+			pathFlag == templateFile && strings.Contains(value, templateAction) {
+			continue
+		}
+		if !tenantOwns(value, tenantFile, tenantArg, ownDir) {
+			return nil, crossTenantError(tenantArg, pathFlag, value)
+		}
+	}
+	if fileNameArg := args[userFileName]; len(fileNameArg) > 0 && !samePath(fileNameArg, tenantFile) {
+		return nil, crossTenantError(tenantArg, userFileName, fileNameArg)
+	}
+	tenantArgs := make(Arguments, len(args))
+	for key, value := range args {
+		tenantArgs[key] = value
+	}
+	tenantArgs[userFileName] = tenantFile
+	return tenantArgs, nil
+}
+
+// tenantOwns reports whether path belongs to the tenant: anything under its
+// directory when the pattern gives every tenant one, otherwise a file named
+// <tenant>.<anything> next to its users file, which no other tenant name can
+// produce.
+func tenantOwns(path, tenantFile, tenantArg string, ownDir bool) bool {
+	dir, err := filepath.Abs(filepath.Dir(tenantFile))
+	if err != nil {
+		return false
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if ownDir {
+		return true
+	}
+	return filepath.Dir(rel) == "." && strings.HasPrefix(rel, tenantArg+".")
+}
+
+func crossTenantError(tenantArg, flagName, value string) error {
+	return newOperationError(CodePermissionDenied,
+		fmt.Errorf("Tenant %s can only use its own users file, -%s %s is refused", tenantArg, flagName, value),
+		map[string]string{tenant: tenantArg, "flag": flagName, "value": value})
+}
+
+func listTenants(args Arguments, writer io.Writer) error {
+	pattern, err := tenantFilePattern(args)
+	if err != nil {
+		return err
+	}
+	matches, err := filepath.Glob(strings.Replace(pattern, tenantPlaceholder, "*", 1))
+	if err != nil {
+		return invalidFlagError(tenantPattern, pattern)
+	}
+	prefix, suffix, _ := strings.Cut(pattern, tenantPlaceholder)
+	tenants := []string{}
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(match, filepath.FromSlash(prefix)), filepath.FromSlash(suffix))
+		if tenantName.MatchString(name) {
+			tenants = append(tenants, name)
+		}
+	}
+	sort.Strings(tenants)
+	tenantsData, err := json.Marshal(tenants)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(tenantsData)
+	return nil
+}
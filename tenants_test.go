@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTenantUsesOwnFile(t *testing.T) {
+	var buffer bytes.Buffer
+	pattern := filepath.Join(t.TempDir(), "users-%s.json")
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}", "tenant": "acme", "tenantPattern": pattern}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	args = Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}", "tenant": "globex", "tenantPattern": pattern}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(filepath.Dir(pattern), "users-acme.json"))
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+
+	buffer.Reset()
+	err = Perform(Arguments{"operation": "tenants", "tenantPattern": pattern}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[\"acme\",\"globex\"]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestCrossTenantFileIsRefused(t *testing.T) {
+	var buffer bytes.Buffer
+	pattern := filepath.Join(t.TempDir(), "users-%s.json")
+
+	args := Arguments{"operation": "list", "tenant": "acme", "tenantPattern": pattern, "fileName": filepath.Join(filepath.Dir(pattern), "users-globex.json")}
+	err := Perform(args, &buffer)
+	if ErrorCodeOf(err) != CodePermissionDenied {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodePermissionDenied, ErrorCodeOf(err))
+	}
+}
+
+func TestCrossTenantPathFlagsAreRefused(t *testing.T) {
+	var buffer bytes.Buffer
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "%s.json")
+	globexFile := filepath.Join(dir, "globex.json")
+
+	cases := []Arguments{
+		{"operation": "export", "output": globexFile},
+		{"operation": "diff", "from": globexFile},
+		{"operation": "synthesize", "like": filepath.Join(dir, "..", "users.json")},
+		{"operation": "importSql", "input": filepath.Join(dir, "globex.sql")},
+		{"operation": "remove", "idsFile": filepath.Join(dir, "globex.ids")},
+	}
+	for _, args := range cases {
+		args["tenant"] = "acme"
+		args["tenantPattern"] = pattern
+		err := Perform(args, &buffer)
+		if ErrorCodeOf(err) != CodePermissionDenied {
+			t.Errorf("Expect error code of %v to be '%s', but got '%s'", args, CodePermissionDenied, ErrorCodeOf(err))
+		}
+	}
+}
+
+func TestTenantExportsNextToOwnFile(t *testing.T) {
+	var buffer bytes.Buffer
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "%s.json")
+
+	args := Arguments{"operation": "add", "item": "{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}", "tenant": "acme", "tenantPattern": pattern}
+	err := Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	args = Arguments{"operation": "export", "output": filepath.Join(dir, "acme.export.json"), "tenant": "acme", "tenantPattern": pattern}
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(dir, "acme.export.json"))
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
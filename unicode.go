@@ -0,0 +1,27 @@
+package main
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var caseFolder = cases.Fold()
+
+func normalizeText(text string) string {
+	return norm.NFC.String(text)
+}
+
+func foldText(text string) string {
+	return caseFolder.String(norm.NFC.String(text))
+}
+
+func normalizeUserText(user *User) {
+	user.Email = normalizeText(user.Email)
+	if user.Address != nil {
+		user.Address.Street = normalizeText(user.Address.Street)
+		user.Address.City = normalizeText(user.Address.City)
+	}
+	for metaKey, metaValue := range user.Metadata {
+		user.Metadata[metaKey] = normalizeText(metaValue)
+	}
+}
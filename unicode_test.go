@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNormalizeUserText(t *testing.T) {
+	user := User{Email: "jose\u0301@test.com", Address: &Address{City: "Mu\u0308nchen", Country: "DE"}}
+
+	normalizeUserText(&user)
+
+	if user.Email != "jos\u00e9@test.com" {
+		t.Errorf("Expect email to be NFC normalized, but got %q", user.Email)
+	}
+	if user.Address.City != "M\u00fcnchen" {
+		t.Errorf("Expect city to be NFC normalized, but got %q", user.Address.City)
+	}
+}
+
+func TestFoldText(t *testing.T) {
+	if foldText("JOSE\u0301") != foldText("jos\u00e9") {
+		t.Error("Expect decomposed upper case and composed lower case to fold to the same value")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/mail"
+)
+
+const (
+	validateOp = "validate"
+	maxSaneAge = 150
+)
+
+type validationReport struct {
+	Valid    bool                `json:"valid"`
+	Users    int                 `json:"users"`
+	Problems []validationProblem `json:"problems"`
+}
+
+type validationProblem struct {
+	Index   int    `json:"index"`
+	Id      string `json:"id"`
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+}
+
+// validateUsersFile checks every record of the users file, including the
+// enabled rules, and writes a report. Problems are returned as an INVALID_DATA
+// error so the CLI exits non-zero.
+func validateUsersFile(enabledRules []validationRule, storage *fileStorage, writer io.Writer) error {
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	report := validationReport{Users: len(users), Problems: []validationProblem{}}
+	firstIndex := map[string]int{}
+	for i, user := range users {
+		addProblem := func(field, problem string) {
+			report.Problems = append(report.Problems, validationProblem{Index: i, Id: user.Id, Field: field, Problem: problem})
+		}
+		if len(user.Id) == 0 {
+			addProblem(id, "id is empty")
+		} else if first, ok := firstIndex[user.Id]; ok {
+			addProblem(id, fmt.Sprintf("id duplicates the record at index %d", first))
+		} else {
+			firstIndex[user.Id] = i
+		}
+		if address, err := mail.ParseAddress(user.Email); err != nil || address.Address != user.Email {
+			addProblem(email, "email is not a valid address")
+		}
+		if user.Age > maxSaneAge {
+			addProblem(ageField, fmt.Sprintf("age is greater than %d", maxSaneAge))
+		}
+		for _, rule := range enabledRules {
+			if !rule.match(user) {
+				addProblem(rules, fmt.Sprintf("%s/%s fails %s", rule.pack, rule.name, rule.condition))
+			}
+		}
+	}
+	report.Valid = len(report.Problems) == 0
+	reportData, err := json.Marshal(report)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(reportData)
+	if !report.Valid {
+		return newOperationError(CodeInvalidData, fmt.Errorf("Users file has %d problems", len(report.Problems)),
+			map[string]string{userFileName: storage.fileName})
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestValidateOperation(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34},{\"id\":\"1\",\"email\":\"not an email\",\"age\":31},{\"id\":\"\",\"email\":\"test3@test.com\",\"age\":200}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "validate", "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodeInvalidData {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodeInvalidData, ErrorCodeOf(err))
+	}
+
+	expectedOutput := "{\"valid\":false,\"users\":3,\"problems\":[" +
+		"{\"index\":1,\"id\":\"1\",\"field\":\"id\",\"problem\":\"id duplicates the record at index 0\"}," +
+		"{\"index\":1,\"id\":\"1\",\"field\":\"email\",\"problem\":\"email is not a valid address\"}," +
+		"{\"index\":2,\"id\":\"\",\"field\":\"id\",\"problem\":\"id is empty\"}," +
+		"{\"index\":2,\"id\":\"\",\"field\":\"age\",\"problem\":\"age is greater than 150\"}]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
+
+func TestValidateOperationValidFile(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"test@test.com\",\"age\":34}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "validate", "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "{\"valid\":true,\"users\":1,\"problems\":[]}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
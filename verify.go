@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	verifyEmailsOp     = "verifyEmails"
+	concurrency        = "concurrency"
+	rate               = "rate"
+	defaultConcurrency = 8
+	defaultRate        = 20
+)
+
+var (
+	lookupMX   = net.DefaultResolver.LookupMX
+	lookupHost = net.DefaultResolver.LookupHost
+)
+
+type emailReport struct {
+	Id          string `json:"id"`
+	Email       string `json:"email"`
+	Syntax      bool   `json:"syntax"`
+	Domain      bool   `json:"domain"`
+	MX          bool   `json:"mx"`
+	Deliverable bool   `json:"deliverable"`
+}
+
+type domainCheck struct {
+	once   sync.Once
+	exists bool
+	mx     bool
+}
+
+func verifyEmails(args Arguments, storage *fileStorage, writer io.Writer) error {
+	workers, err := positiveIntArg(args, concurrency, defaultConcurrency)
+	if err != nil {
+		return err
+	}
+	perSecond, err := positiveIntArg(args, rate, defaultRate)
+	if err != nil {
+		return err
+	}
+	users, err := storage.load()
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(perSecond))
+	defer ticker.Stop()
+
+	reports := make([]emailReport, len(users))
+	domains := map[string]*domainCheck{}
+	var domainsMutex sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				user := users[i]
+				report := emailReport{Id: user.Id, Email: user.Email}
+				if _, err := mail.ParseAddress(user.Email); err == nil {
+					report.Syntax = true
+					domain := emailDomain(asciiEmail(user.Email))
+					domainsMutex.Lock()
+					check, ok := domains[domain]
+					if !ok {
+						check = &domainCheck{}
+						domains[domain] = check
+					}
+					domainsMutex.Unlock()
+					check.once.Do(func() {
+						<-ticker.C
+						check.exists, check.mx = checkDomain(storage.ctx, domain)
+					})
+					report.Domain = check.exists
+					report.MX = check.mx
+					report.Deliverable = check.mx
+				}
+				reports[i] = report
+			}
+		}()
+	}
+	for i := range users {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := storage.ctx.Err(); err != nil {
+		return timeoutError(err)
+	}
+
+	reportsData, err := json.Marshal(reports)
+	if err != nil {
+		return newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	writer.Write(reportsData)
+	return nil
+}
+
+func checkDomain(ctx context.Context, domain string) (bool, bool) {
+	records, err := lookupMX(ctx, domain)
+	if err == nil && len(records) > 0 {
+		return true, true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, false
+	}
+	addresses, err := lookupHost(ctx, domain)
+	return err == nil && len(addresses) > 0, false
+}
+
+func positiveIntArg(args Arguments, flagName string, defaultValue int) (int, error) {
+	value := args[flagName]
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, invalidFlagError(flagName, value)
+	}
+	return number, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestVerifyEmailsOperation(t *testing.T) {
+	var buffer bytes.Buffer
+	lookupMX = func(ctx context.Context, name string) ([]*net.MX, error) {
+		if name == "test.com" {
+			return []*net.MX{{Host: "mx.test.com", Pref: 10}}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	defer func() { lookupMX = net.DefaultResolver.LookupMX }()
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"a@test.com\",\"age\":34},{\"id\":\"2\",\"email\":\"b@missing.invalid\",\"age\":31},{\"id\":\"3\",\"email\":\"not an email\",\"age\":22}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"a@test.com\",\"syntax\":true,\"domain\":true,\"mx\":true,\"deliverable\":true}," +
+		"{\"id\":\"2\",\"email\":\"b@missing.invalid\",\"syntax\":true,\"domain\":false,\"mx\":false,\"deliverable\":false}," +
+		"{\"id\":\"3\",\"email\":\"not an email\",\"syntax\":false,\"domain\":false,\"mx\":false,\"deliverable\":false}]"
+	args := Arguments{"operation": "verifyEmails", "rate": "1000", "fileName": fileName}
+
+	err = Perform(args, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+}
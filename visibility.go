@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	visibilitySection = "visibility"
+	unmask            = "unmask"
+	hiddenField       = "hidden"
+	maskedField       = "masked"
+	maskText          = "***"
+)
+
+// fieldPolicies maps a user field to hidden or masked. It is read from the
+// [visibility] section of the config file, one entry per profile:
+//
+//	support = "age:hidden, email:masked"
+//
+// Masked fields are shown in full with -unmask when the [permissions] of the
+// profile allow the unmask pseudo operation.
+type fieldPolicies map[string]string
+
+var visibilityFields = map[string]func(user *User, policy string){
+	"email": func(user *User, policy string) {
+		user.Email, user.EmailAscii = maskEmail(user.Email, policy), ""
+	},
+	"age": func(user *User, policy string) { user.Age = 0 },
+	"phone": func(user *User, policy string) {
+		user.Phone = maskValue(user.Phone, policy)
+	},
+	"dob": func(user *User, policy string) {
+		user.Dob = maskValue(user.Dob, policy)
+	},
+	"address":  func(user *User, policy string) { user.Address = nil },
+	"metadata": func(user *User, policy string) { user.Metadata = nil },
+	"notes":    func(user *User, policy string) { user.Notes = nil },
+}
+
+func loadVisibility(args Arguments) (fieldPolicies, error) {
+	profileArg := args[profile]
+	if len(profileArg) == 0 || len(args[configFile]) == 0 {
+		return nil, nil
+	}
+	settings, err := loadConfig(args[configFile])
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := settings[visibilitySection][profileArg]
+	if !ok {
+		return nil, nil
+	}
+	unmasked := false
+	if args[unmask] == "true" {
+		unmaskArgs := Arguments{operation: unmask, profile: profileArg, configFile: args[configFile]}
+		if err := checkPermission(unmaskArgs); err != nil {
+			return nil, err
+		}
+		unmasked = true
+	}
+	policies := fieldPolicies{}
+	for _, item := range strings.Split(entry, ",") {
+		fieldName, policy, _ := strings.Cut(strings.TrimSpace(item), ":")
+		fieldName, policy = strings.TrimSpace(fieldName), strings.TrimSpace(policy)
+		if _, ok := visibilityFields[fieldName]; !ok || (policy != hiddenField && policy != maskedField) {
+			return nil, newOperationError(CodeValidation, fmt.Errorf("Invalid visibility %s for profile %s", item, profileArg),
+				map[string]string{configFile: args[configFile], profile: profileArg})
+		}
+		if policy == maskedField && unmasked {
+			continue
+		}
+		policies[fieldName] = policy
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return policies, nil
+}
+
+// apply hides or masks the fields of users about to be written as output,
+// never of users that are saved. Age has no masked form and is hidden either
+// way.
+func (policies fieldPolicies) apply(users []User) {
+	for i := range users {
+		for fieldName, policy := range policies {
+			visibilityFields[fieldName](&users[i], policy)
+		}
+	}
+}
+
+// shown returns a copy of users as a writing operation prints them, with the
+// policies and -hideNotes applied that its load left out.
+func (s *fileStorage) shown(users []User) []User {
+	shown := cloneUsers(users)
+	s.masked.apply(shown)
+	if s.hideNotes {
+		stripNotes(shown)
+	}
+	return shown
+}
+
+func maskValue(value, policy string) string {
+	if policy == hiddenField || len(value) == 0 {
+		return ""
+	}
+	runes := []rune(value)
+	return string(runes[:1]) + maskText
+}
+
+// maskEmail keeps the first character and the domain of the address.
+func maskEmail(address, policy string) string {
+	at := strings.LastIndex(address, "@")
+	if policy == hiddenField || at < 0 {
+		return maskValue(address, policy)
+	}
+	return maskValue(address[:at], policy) + address[at:]
+}
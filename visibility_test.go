@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestVisibilityPolicy(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"john@test.com\",\"age\":23,\"phone\":\"+380501234567\"}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("[permissions]\nsupport = \"list,findById,setMeta\"\nadmin = \"*\"\n[visibility]\nsupport = \"age:hidden, email:masked, phone:masked\"\nadmin = \"email:masked\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "list", "profile": "support", "config": configFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "[{\"id\":\"1\",\"email\":\"j***@test.com\",\"age\":0,\"phone\":\"+***\"}]"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	buffer.Reset()
+	err = Perform(Arguments{"operation": "findById", "id": "1", "profile": "admin", "unmask": "true", "config": configFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput = "{\"id\":\"1\",\"email\":\"john@test.com\",\"age\":23,\"phone\":\"+380501234567\"}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+
+	err = Perform(Arguments{"operation": "list", "profile": "support", "unmask": "true", "config": configFileName, "fileName": fileName}, &buffer)
+	if ErrorCodeOf(err) != CodePermissionDenied {
+		t.Errorf("Expect error code to be '%s', but got '%s'", CodePermissionDenied, ErrorCodeOf(err))
+	}
+
+	err = Perform(Arguments{"operation": "setMeta", "id": "1", "key": "team", "value": "sre", "profile": "support", "config": configFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"john@test.com\",\"age\":23,\"phone\":\"+380501234567\",\"metadata\":{\"team\":\"sre\"}}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
+
+func TestVisibilityPolicyOnPatch(t *testing.T) {
+	var buffer bytes.Buffer
+	configFileName := "config.toml"
+
+	err := ioutil.WriteFile(fileName, []byte("[{\"id\":\"1\",\"email\":\"john@test.com\",\"age\":23}]"), filePermission)
+	defer os.Remove(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	err = ioutil.WriteFile(configFileName, []byte("[permissions]\nsupport = \"patch\"\n[visibility]\nsupport = \"age:hidden, email:masked\"\n"), filePermission)
+	defer os.Remove(configFileName)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = Perform(Arguments{"operation": "patch", "id": "1", "item": "{\"age\":24}", "profile": "support", "config": configFileName, "fileName": fileName}, &buffer)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedOutput := "{\"id\":\"1\",\"email\":\"j***@test.com\",\"age\":0}"
+	if buffer.String() != expectedOutput {
+		t.Errorf("Expect output to be '%s', but got '%s'", expectedOutput, buffer.String())
+	}
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFileContent := "[{\"id\":\"1\",\"email\":\"john@test.com\",\"age\":24}]"
+	if string(bytes) != expectedFileContent {
+		t.Errorf("Expect file content to be '%s', but got '%s'", expectedFileContent, bytes)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+)
+
+const (
+	xmlFormat      = "xml"
+	defaultXMLRoot = "users"
+)
+
+var xmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// writeUsersXML writes a <user> element per user inside the root element,
+// users unless set with -format xml:<root>. Fields become child elements, and
+// keys that are not valid element names, such as some metadata keys, become
+// <entry key="..."> elements. Array items are <item> elements.
+func writeUsersXML(users []User, fields []computedField, root string, writer io.Writer) error {
+	if len(root) == 0 {
+		root = defaultXMLRoot
+	}
+	if !xmlName.MatchString(root) {
+		return invalidFlagError(format, xmlFormat+":"+root)
+	}
+	usersData, err := marshalUsers(users, fields)
+	if err != nil {
+		return err
+	}
+	node, err := decodeJSONNode(usersData)
+	if err != nil {
+		return err
+	}
+	var xmlData bytes.Buffer
+	xmlData.WriteString(xml.Header)
+	xmlData.WriteString("<" + root + ">")
+	for _, user := range node.values {
+		writeXMLElement(&xmlData, "user", user)
+	}
+	xmlData.WriteString("</" + root + ">\n")
+	writer.Write(xmlData.Bytes())
+	return nil
+}
+
+func writeXMLElement(xmlData *bytes.Buffer, name string, node *jsonNode) {
+	end := name
+	if !xmlName.MatchString(name) {
+		xmlData.WriteString(`<entry key="`)
+		xml.EscapeText(xmlData, []byte(name))
+		xmlData.WriteString(`">`)
+		end = "entry"
+	} else {
+		xmlData.WriteString("<" + name + ">")
+	}
+	switch {
+	case node.object:
+		for i, child := range node.values {
+			writeXMLElement(xmlData, node.keys[i], child)
+		}
+	case node.array:
+		for _, child := range node.values {
+			writeXMLElement(xmlData, "item", child)
+		}
+	case node.text || node.scalar != "null":
+		xml.EscapeText(xmlData, []byte(node.scalar))
+	}
+	xmlData.WriteString("</" + end + ">")
+}
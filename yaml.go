@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const yamlFormat = "yaml"
+
+// jsonNode is a decoded JSON value that keeps the key order of objects, for
+// the formats converted from marshalled JSON.
+type jsonNode struct {
+	scalar string
+	text   bool
+	keys   []string
+	values []*jsonNode
+	object bool
+	array  bool
+}
+
+var yamlPlainText = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_ ./+@-]*$`)
+
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "null": true, "yes": true, "no": true, "on": true, "off": true, "y": true, "n": true, "~": true,
+}
+
+func writeUsersYAML(users []User, fields []computedField, writer io.Writer) error {
+	usersData, err := marshalUsers(users, fields)
+	if err != nil {
+		return err
+	}
+	return writeYAML(usersData, writer)
+}
+
+// writeYAML converts marshalled JSON to a YAML block document.
+func writeYAML(jsonData []byte, writer io.Writer) error {
+	node, err := decodeJSONNode(jsonData)
+	if err != nil {
+		return err
+	}
+	var yamlData bytes.Buffer
+	node.writeYAML(&yamlData, "")
+	writer.Write(yamlData.Bytes())
+	return nil
+}
+
+func decodeJSONNode(jsonData []byte) (*jsonNode, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	node, err := decodeNextNode(decoder)
+	if err != nil {
+		return nil, newOperationError(CodeInternal, fmt.Errorf(marshalingErrorMsg, err), nil)
+	}
+	return node, nil
+}
+
+func decodeNextNode(decoder *json.Decoder) (*jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &jsonNode{}
+	switch value := token.(type) {
+	case json.Delim:
+		node.object, node.array = value == '{', value == '['
+		for decoder.More() {
+			if node.object {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			child, err := decodeNextNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, child)
+		}
+		_, err = decoder.Token()
+		return node, err
+	case string:
+		node.scalar, node.text = value, true
+	case nil:
+		node.scalar = "null"
+	default:
+		node.scalar = fmt.Sprint(value)
+	}
+	return node, nil
+}
+
+func (node *jsonNode) collection() bool {
+	return (node.object || node.array) && len(node.values) > 0
+}
+
+func (node *jsonNode) writeYAML(yamlData *bytes.Buffer, indent string) {
+	switch {
+	case node.object && len(node.values) == 0:
+		yamlData.WriteString("{}\n")
+	case node.array && len(node.values) == 0:
+		yamlData.WriteString("[]\n")
+	case node.object:
+		for i, child := range node.values {
+			if i > 0 {
+				yamlData.WriteString(indent)
+			}
+			yamlData.WriteString(yamlString(node.keys[i]) + ":")
+			if child.collection() {
+				yamlData.WriteString("\n" + indent + "  ")
+				child.writeYAML(yamlData, indent+"  ")
+				continue
+			}
+			yamlData.WriteString(" ")
+			child.writeYAML(yamlData, indent)
+		}
+	case node.array:
+		for i, child := range node.values {
+			if i > 0 {
+				yamlData.WriteString(indent)
+			}
+			yamlData.WriteString("- ")
+			child.writeYAML(yamlData, indent+"  ")
+		}
+	case node.text:
+		yamlData.WriteString(yamlString(node.scalar) + "\n")
+	default:
+		yamlData.WriteString(node.scalar + "\n")
+	}
+}
+
+// yamlString leaves text plain unless YAML would read it as another type or
+// as syntax, and quotes it the JSON way otherwise, which YAML accepts.
+func yamlString(text string) string {
+	if yamlPlainText.MatchString(text) && !yamlReserved[strings.ToLower(text)] && strings.TrimSpace(text) == text {
+		return text
+	}
+	quoted, _ := json.Marshal(text)
+	return string(quoted)
+}